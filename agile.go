@@ -0,0 +1,389 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+	"github.com/tears-of-noobs/gojira"
+)
+
+const (
+	agileBoardScrum = "scrum"
+
+	agileSprintActive = "active"
+	agileSprintFuture = "future"
+	agileSprintClosed = "closed"
+
+	// agileConstraintNone means that board columns have no WIP limits,
+	// other constraints are issue count with or without subtasks.
+	agileConstraintNone = "none"
+)
+
+var errNoBoard = errors.New(
+	"board is not specified, use --board flag or board_id setting",
+)
+
+type agileBoard struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type agileBoardConfiguration struct {
+	ColumnConfig struct {
+		Columns        []agileColumn `json:"columns"`
+		ConstraintType string        `json:"constraintType"`
+	} `json:"columnConfig"`
+	Estimation struct {
+		Type  string `json:"type"`
+		Field struct {
+			FieldID     string `json:"fieldId"`
+			DisplayName string `json:"displayName"`
+		} `json:"field"`
+	} `json:"estimation"`
+}
+
+type agileColumn struct {
+	Name     string `json:"name"`
+	Statuses []struct {
+		ID string `json:"id"`
+	} `json:"statuses"`
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type agileSprint struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	Goal      string `json:"goal"`
+}
+
+type agileIssues struct {
+	StartAt    int     `json:"startAt"`
+	MaxResults int     `json:"maxResults"`
+	Total      int     `json:"total"`
+	Issues     []Issue `json:"issues"`
+}
+
+type status struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// getAgileURL returns URL of Jira Software REST API which is located next to
+// the configured Jira REST API.
+func getAgileURL() string {
+	index := strings.Index(gojira.BaseURL, "/rest/api/")
+	if index < 0 {
+		return gojira.BaseURL + "/rest/agile/1.0"
+	}
+
+	return gojira.BaseURL[:index] + "/rest/agile/1.0"
+}
+
+func requestAgile(method, path string, payload, result interface{}) error {
+	return requestURL(method, getAgileURL()+path, payload, result)
+}
+
+func getAgileBoard(boardID int) (*agileBoard, error) {
+	var board agileBoard
+	err := requestAgile("GET", "/board/"+strconv.Itoa(boardID), nil, &board)
+	if err != nil {
+		return nil, err
+	}
+
+	return &board, nil
+}
+
+func getAgileBoardConfiguration(
+	boardID int,
+) (*agileBoardConfiguration, error) {
+	var configuration agileBoardConfiguration
+	err := requestAgile(
+		"GET", "/board/"+strconv.Itoa(boardID)+"/configuration",
+		nil, &configuration,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &configuration, nil
+}
+
+// getAgileBoardStages returns kanban stages which match columns of the Jira
+// Software board, columns without statuses are skipped.
+func getAgileBoardStages(boardID int) ([]Stage, error) {
+	configuration, err := getAgileBoardConfiguration(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAgileColumnStages(configuration)
+}
+
+func getAgileColumnStages(
+	configuration *agileBoardConfiguration,
+) ([]Stage, error) {
+	var statuses []status
+	err := requestAPI("GET", "/status", nil, &statuses)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to get statuses",
+		)
+	}
+
+	statusNames := map[string]string{}
+	for _, status := range statuses {
+		statusNames[status.ID] = status.Name
+	}
+
+	withLimits := configuration.ColumnConfig.ConstraintType != "" &&
+		configuration.ColumnConfig.ConstraintType != agileConstraintNone
+
+	stages := []Stage{}
+	for _, column := range configuration.ColumnConfig.Columns {
+		if len(column.Statuses) == 0 {
+			continue
+		}
+
+		stage := Stage{
+			Name:        column.Name,
+			KanbanOrder: len(stages) + 1,
+		}
+
+		for _, status := range column.Statuses {
+			name, ok := statusNames[status.ID]
+			if !ok {
+				name = status.ID
+			}
+
+			stage.Statuses = append(stage.Statuses, name)
+		}
+
+		if withLimits {
+			stage.WIPLimit = WIPLimit{Min: column.Min, Max: column.Max}
+		}
+
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
+
+// getSprints returns sprints of board in specified comma-separated states:
+// active, future or closed.
+func getSprints(boardID int, states string) ([]agileSprint, error) {
+	sprints := []agileSprint{}
+
+	for {
+		var reply struct {
+			IsLast bool          `json:"isLast"`
+			Values []agileSprint `json:"values"`
+		}
+
+		err := requestAgile(
+			"GET",
+			"/board/"+strconv.Itoa(boardID)+"/sprint"+
+				"?state="+url.QueryEscape(states)+
+				"&startAt="+strconv.Itoa(len(sprints)),
+			nil, &reply,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sprints = append(sprints, reply.Values...)
+
+		if reply.IsLast || len(reply.Values) == 0 {
+			return sprints, nil
+		}
+	}
+}
+
+func getActiveSprints(boardID int) ([]agileSprint, error) {
+	return getSprints(boardID, agileSprintActive)
+}
+
+// findSprint returns sprint of board by identifier or case-insensitive
+// name.
+func findSprint(boardID int, name string) (*agileSprint, error) {
+	sprints, err := getSprints(
+		boardID,
+		agileSprintActive+","+agileSprintFuture+","+agileSprintClosed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sprint := range sprints {
+		if strconv.Itoa(sprint.ID) == name || strings.EqualFold(sprint.Name, name) {
+			return &sprint, nil
+		}
+	}
+
+	return nil, fmt.Errorf("sprint not found: %s", name)
+}
+
+func getSprintIssuesPath(boardID int, sprintID int) string {
+	return "/board/" + strconv.Itoa(boardID) +
+		"/sprint/" + strconv.Itoa(sprintID) + "/issue"
+}
+
+// getAllSprintIssues returns all issues of sprint, issues are requested page
+// by page, request should contain other query parameters.
+func getAllSprintIssues(
+	boardID int,
+	sprintID int,
+	request string,
+) ([]Issue, error) {
+	return getAgileIssues(getSprintIssuesPath(boardID, sprintID), request, 0)
+}
+
+// getAgileIssues returns issues of board or sprint located at path, issues
+// are requested page by page until limit is reached, all issues are returned
+// if limit is zero. Request should contain other query parameters.
+func getAgileIssues(path string, request string, limit int) ([]Issue, error) {
+	issues := []Issue{}
+
+	for {
+		page := request + "&startAt=" + strconv.Itoa(len(issues))
+		if limit > 0 {
+			size := limit - len(issues)
+			if size > searchPageSize {
+				size = searchPageSize
+			}
+
+			page += "&maxResults=" + strconv.Itoa(size)
+		}
+
+		var reply agileIssues
+		err := requestAgile("GET", path+page, nil, &reply)
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, reply.Issues...)
+
+		if limit > 0 && len(issues) >= limit {
+			return issues[:limit], nil
+		}
+
+		if len(reply.Issues) == 0 || len(issues) >= reply.Total {
+			return issues, nil
+		}
+	}
+}
+
+// moveIssuesToSprint moves issues to the sprint or to the backlog if sprint
+// is nil.
+func moveIssuesToSprint(sprint *agileSprint, keys []string) error {
+	path := "/backlog/issue"
+	if sprint != nil {
+		path = "/sprint/" + strconv.Itoa(sprint.ID) + "/issue"
+	}
+
+	return requestAgile(
+		"POST", path,
+		map[string]interface{}{"issues": keys},
+		nil,
+	)
+}
+
+// getAgileBoardIssues returns issues of active sprints of scrum board or
+// issues of kanban board in board rank order, query is applied to the
+// issues if not empty. Issues of specified sprint are returned instead of
+// active ones if sprint name is not empty. All issues are returned if limit
+// is zero, otherwise limit is applied to issues of all sprints together.
+func getAgileBoardIssues(
+	boardID int,
+	sprintName string,
+	query string,
+	limit int,
+	extraFields ...string,
+) ([]Issue, []agileSprint, error) {
+	board, err := getAgileBoard(boardID)
+	if err != nil {
+		return nil, nil, karma.Format(
+			err,
+			"unable to get board: %d", boardID,
+		)
+	}
+
+	fields := append(append([]string{}, searchFields...), extraFields...)
+
+	request := "?fields=" + strings.Join(fields, ",")
+	if query != "" {
+		request += "&jql=" + url.QueryEscape(query)
+	}
+
+	if sprintName != "" {
+		sprint, err := findSprint(boardID, sprintName)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		issues, err := getAgileIssues(
+			getSprintIssuesPath(boardID, sprint.ID), request, limit,
+		)
+		if err != nil {
+			return nil, nil, karma.Format(
+				err,
+				"unable to get issues of sprint: %s", sprint.Name,
+			)
+		}
+
+		return issues, []agileSprint{*sprint}, nil
+	}
+
+	if board.Type != agileBoardScrum {
+		issues, err := getAgileIssues(
+			"/board/"+strconv.Itoa(boardID)+"/issue", request, limit,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return issues, nil, nil
+	}
+
+	sprints, err := getActiveSprints(boardID)
+	if err != nil {
+		return nil, nil, karma.Format(
+			err,
+			"unable to get active sprints of board: %d", boardID,
+		)
+	}
+
+	issues := []Issue{}
+	for _, sprint := range sprints {
+		sprintLimit := 0
+		if limit > 0 {
+			sprintLimit = limit - len(issues)
+			if sprintLimit <= 0 {
+				break
+			}
+		}
+
+		sprintIssues, err := getAgileIssues(
+			getSprintIssuesPath(boardID, sprint.ID), request, sprintLimit,
+		)
+		if err != nil {
+			return nil, nil, karma.Format(
+				err,
+				"unable to get issues of sprint: %s", sprint.Name,
+			)
+		}
+
+		issues = append(issues, sprintIssues...)
+	}
+
+	return issues, sprints, nil
+}
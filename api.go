@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+	"github.com/tears-of-noobs/gojira"
+)
+
+type apiError struct {
+	Code          int               `json:"-"`
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+func (err apiError) Error() string {
+	messages := err.Messages()
+	if len(messages) == 0 {
+		return fmt.Sprintf("unexpected reply code: %d", err.Code)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Messages returns error messages and field errors as "field: message".
+func (err apiError) Messages() []string {
+	messages := append([]string{}, err.ErrorMessages...)
+
+	fields := []string{}
+	for field := range err.Errors {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		messages = append(messages, field+": "+err.Errors[field])
+	}
+
+	return messages
+}
+
+func requestAPI(method, path string, payload, result interface{}) error {
+	return requestURL(method, gojira.BaseURL+path, payload, result)
+}
+
+func requestURL(method, url string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewBuffer(encoded)
+	}
+
+	code, reply := gojira.RawRequest(url, method, body)
+	if code < http.StatusOK || code >= http.StatusMultipleChoices {
+		failure := apiError{Code: code}
+
+		// reply can be a html page, so decoding error is not important
+		_ = json.Unmarshal(reply, &failure)
+
+		return failure
+	}
+
+	if result == nil || len(reply) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(reply, result)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to decode reply of %s %s", method, url,
+		)
+	}
+
+	return nil
+}
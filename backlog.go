@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	rankTop    = "top"
+	rankBottom = "bottom"
+	rankBefore = "before"
+	rankAfter  = "after"
+)
+
+type rankResult struct {
+	Entries []struct {
+		IssueKey string   `json:"issueKey"`
+		Status   int      `json:"status"`
+		Errors   []string `json:"errors"`
+	} `json:"entries"`
+}
+
+// getBacklogIssues returns issues of board backlog in rank order starting
+// from specified position.
+func getBacklogIssues(
+	boardID int,
+	query string,
+	startAt int,
+	limit int,
+	fields ...string,
+) (*agileIssues, error) {
+	request := "?startAt=" + strconv.Itoa(startAt) +
+		"&maxResults=" + strconv.Itoa(limit)
+	if len(fields) > 0 {
+		request += "&fields=" + strings.Join(fields, ",")
+	}
+
+	if query != "" {
+		request += "&jql=" + url.QueryEscape(query)
+	}
+
+	var reply agileIssues
+	err := requestAgile(
+		"GET", "/board/"+strconv.Itoa(boardID)+"/backlog"+request,
+		nil, &reply,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reply, nil
+}
+
+// setIssueEstimates fills estimates of issues using board estimation field.
+func setIssueEstimates(issues []Issue, fieldID string) error {
+	for i := range issues {
+		var estimate float64
+		ok, err := issues[i].GetField(fieldID, &estimate)
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to get estimate of issue: %s", issues[i].Key,
+			)
+		}
+
+		if ok {
+			issues[i].Estimate = &estimate
+		}
+	}
+
+	return nil
+}
+
+// rankIssues ranks issues before or after specified issue.
+func rankIssues(keys []string, position string, target string) error {
+	payload := map[string]interface{}{
+		"issues": keys,
+	}
+
+	if position == rankBefore {
+		payload["rankBeforeIssue"] = target
+	} else {
+		payload["rankAfterIssue"] = target
+	}
+
+	var result rankResult
+	err := requestAgile("PUT", "/issue/rank", payload, &result)
+	if err != nil {
+		return err
+	}
+
+	// Jira replies with multi-status if some issues are not ranked
+	failures := []string{}
+	for _, entry := range result.Entries {
+		if entry.Status >= 300 {
+			failures = append(
+				failures,
+				entry.IssueKey+": "+strings.Join(entry.Errors, ", "),
+			)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// getRankEdge returns key of the first or the last backlog issue which is not
+// one of the ranked issues.
+func getRankEdge(boardID int, position string, keys []string) (string, error) {
+	ranked := map[string]bool{}
+	for _, key := range keys {
+		ranked[key] = true
+	}
+
+	startAt := 0
+	if position == rankBottom {
+		head, err := getBacklogIssues(boardID, "", 0, 0, "key")
+		if err != nil {
+			return "", err
+		}
+
+		startAt = head.Total - len(keys) - 1
+		if startAt < 0 {
+			startAt = 0
+		}
+	}
+
+	backlog, err := getBacklogIssues(boardID, "", startAt, len(keys)+1, "key")
+	if err != nil {
+		return "", err
+	}
+
+	issues := backlog.Issues
+	if position == rankBottom {
+		for i := len(issues) - 1; i >= 0; i-- {
+			if !ranked[issues[i].Key] {
+				return issues[i].Key, nil
+			}
+		}
+	} else {
+		for _, issue := range issues {
+			if !ranked[issue.Key] {
+				return issue.Key, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("backlog has no other issues to rank against")
+}
+
+func handleBacklogMode(
+	boardID int,
+	config *Configuration,
+	query string,
+	onlyMy bool,
+	limit int,
+	showName bool,
+	onlySummary bool,
+) error {
+	configuration, err := getAgileBoardConfiguration(boardID)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get configuration of board: %d", boardID,
+		)
+	}
+
+	estimationField := configuration.Estimation.Field.FieldID
+
+	fields := append([]string{}, searchFields...)
+	if estimationField != "" {
+		fields = append(fields, estimationField)
+	}
+
+	chunks := []string{}
+	if query != "" {
+		chunks = append(chunks, "("+query+")")
+	}
+
+	if onlyMy {
+		chunks = append(chunks, "assignee = currentUser()")
+	}
+
+	backlog, err := getBacklogIssues(
+		boardID, strings.Join(chunks, " AND "), 0, limit, fields...,
+	)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get backlog of board: %d", boardID,
+		)
+	}
+
+	if estimationField != "" {
+		err = setIssueEstimates(backlog.Issues, estimationField)
+		if err != nil {
+			return err
+		}
+	}
+
+	activeIssueKey, err := getActiveIssueKey()
+	if err != nil {
+		return err
+	}
+
+	return displayIssues(
+		backlog.Issues,
+		activeIssueKey, showName, onlySummary,
+		config.Workflow,
+		nil,
+		BacklogTemplate,
+	)
+}
+
+// handleRankMode ranks issues to the top or to the bottom of the board
+// backlog or before or after specified issue.
+func handleRankMode(
+	boardID int,
+	keys []string,
+	position string,
+	target string,
+) error {
+	switch position {
+	case rankTop, rankBottom:
+		var err error
+		target, err = getRankEdge(boardID, position, keys)
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to get %s of backlog of board: %d", position, boardID,
+			)
+		}
+
+		if position == rankTop {
+			position = rankBefore
+		} else {
+			position = rankAfter
+		}
+	}
+
+	err := rankIssues(keys, position, target)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to rank issues: %s", strings.Join(keys, ", "),
+		)
+	}
+
+	fmt.Printf(
+		"Issues %s ranked %s %s\n",
+		strings.Join(keys, ", "), position, target,
+	)
+
+	return nil
+}
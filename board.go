@@ -2,19 +2,108 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"sort"
+	"strconv"
+	"text/template"
 
-	"github.com/olekukonko/tablewriter"
-	"github.com/tears-of-noobs/gojira"
+	"github.com/reconquest/karma-go"
+	"github.com/seletskiy/tplutil"
 )
 
 type kanbanBoard struct {
-	issues       []gojira.Issue
+	kanbanOptions
+
+	issues       []Issue
 	stages       []Stage
 	tableRows    [][]string
 	tableHeaders []string
-	showSummary  bool
-	showName     bool
+	stageCounts  map[string]int
+
+	// laneRows are indexes of table rows which are swimlane headers.
+	laneRows map[int]bool
+
+	// templates are card templates by stage names, empty name is for the
+	// default template.
+	templates map[string]*template.Template
+
+	// unmappedIssues are issues which statuses have no columns on the
+	// board.
+	unmappedIssues []Issue
+}
+
+type kanbanOptions struct {
+	showSummary bool
+	showName    bool
+	swimlane    string
+	layout      string
+	wrap        bool
+	template    string
+	unmapped    string
+}
+
+const (
+	kanbanUnmappedColumn = "column"
+	kanbanUnmappedFooter = "footer"
+	kanbanUnmappedHide   = "hide"
+
+	// kanbanOtherColumn is a name of column for issues which statuses have
+	// no columns on the board.
+	kanbanOtherColumn = "Other"
+)
+
+var DefaultKanbanTemplate = template.Must(
+	template.New("kanban").Parse(
+		"{{.mark}}{{.key}}" +
+			"{{if .show_summary}} {{.summary}}{{end}}" +
+			"{{if .show_name}} ({{.assignee_name}}){{end}}",
+	),
+)
+
+type swimlane struct {
+	name   string
+	order  int
+	issues []Issue
+}
+
+// swimlaneGetters return names of swimlanes for the issue, empty list means
+// the issue goes to the default swimlane.
+var swimlaneGetters = map[string]func(issue Issue) ([]string, error){
+	"assignee": func(issue Issue) ([]string, error) {
+		if issue.Fields.Assignee.DisplayName == "" {
+			return nil, nil
+		}
+
+		return []string{issue.Fields.Assignee.DisplayName}, nil
+	},
+	"epic": func(issue Issue) ([]string, error) {
+		epic, err := getIssueEpic(&issue)
+		if err != nil || epic == "" {
+			return nil, err
+		}
+
+		return []string{epic}, nil
+	},
+	"priority": func(issue Issue) ([]string, error) {
+		if issue.Extra.Priority.Name == "" {
+			return nil, nil
+		}
+
+		return []string{issue.Extra.Priority.Name}, nil
+	},
+	"type": func(issue Issue) ([]string, error) {
+		return []string{issue.Fields.IssueType.Name}, nil
+	},
+	"label": func(issue Issue) ([]string, error) {
+		return issue.Fields.Labels, nil
+	},
+}
+
+var defaultSwimlaneNames = map[string]string{
+	"assignee": "Unassigned",
+	"epic":     "No epic",
+	"priority": "No priority",
+	"type":     "No type",
+	"label":    "No label",
 }
 
 type KanbanOrderSortableStages []Stage
@@ -32,26 +121,90 @@ func (sortable KanbanOrderSortableStages) Less(i, j int) bool {
 }
 
 func NewKanbanBoard(
-	issues []gojira.Issue,
+	issues []Issue,
 	workflowStages []Stage,
-	showSummary bool,
-	showName bool,
+	options kanbanOptions,
 ) (kanbanBoard, error) {
 	if len(workflowStages) == 0 {
 		return kanbanBoard{}, fmt.Errorf("kanban stages are not defined")
 	}
 
+	_, ok := swimlaneGetters[options.swimlane]
+	if options.swimlane != "" && !ok {
+		return kanbanBoard{}, fmt.Errorf(
+			"unknown swimlane: %s, expected one of: "+
+				"assignee, epic, priority, type, label",
+			options.swimlane,
+		)
+	}
+
+	switch options.layout {
+	case "":
+		options.layout = kanbanLayoutAuto
+	case kanbanLayoutAuto, kanbanLayoutTable, kanbanLayoutStacked:
+	default:
+		return kanbanBoard{}, fmt.Errorf(
+			"unknown kanban layout: %s, expected one of: "+
+				"auto, table, stacked",
+			options.layout,
+		)
+	}
+
+	switch options.unmapped {
+	case "":
+		options.unmapped = kanbanUnmappedFooter
+	case kanbanUnmappedColumn, kanbanUnmappedFooter, kanbanUnmappedHide:
+	default:
+		return kanbanBoard{}, fmt.Errorf(
+			"unknown way to show unmapped statuses: %s, expected one of: "+
+				"column, footer, hide",
+			options.unmapped,
+		)
+	}
+
 	board := kanbanBoard{
-		issues:      issues,
-		stages:      workflowStages,
-		showSummary: showSummary,
-		showName:    showName,
+		kanbanOptions: options,
+		issues:        issues,
+		stages:        workflowStages,
+		templates: map[string]*template.Template{
+			"": DefaultKanbanTemplate,
+		},
+	}
+
+	if options.template != "" {
+		tpl, err := template.New("kanban").Parse(options.template)
+		if err != nil {
+			return kanbanBoard{}, karma.Format(
+				err,
+				"unable to parse kanban template",
+			)
+		}
+
+		board.templates[""] = tpl
+	}
+
+	for _, stage := range workflowStages {
+		if stage.KanbanTemplate == "" {
+			continue
+		}
+
+		tpl, err := template.New(stage.Name).Parse(stage.KanbanTemplate)
+		if err != nil {
+			return kanbanBoard{}, karma.Format(
+				err,
+				"unable to parse kanban template: %s", stage.Name,
+			)
+		}
+
+		board.templates[stage.Name] = tpl
 	}
 
 	return board, nil
 }
 
-func (board *kanbanBoard) GenerateBoardData(activeIssueKey string) {
+func (board *kanbanBoard) GenerateBoardData(activeIssueKey string) error {
+	var err error
+
 	board.tableHeaders = []string{}
 	for _, stage := range board.stages {
 		if stage.KanbanOrder != 0 {
@@ -59,29 +212,156 @@ func (board *kanbanBoard) GenerateBoardData(activeIssueKey string) {
 		}
 	}
 
-	stageIssuesMap := map[string][]gojira.Issue{}
+	board.unmappedIssues = []Issue{}
+	for _, issue := range board.issues {
+		if !board.isMapped(issue) {
+			board.unmappedIssues = append(board.unmappedIssues, issue)
+		}
+	}
+
+	if len(board.unmappedIssues) > 0 && board.unmapped == kanbanUnmappedColumn {
+		board.tableHeaders = append(board.tableHeaders, kanbanOtherColumn)
+	}
+
+	board.stageCounts = map[string]int{}
+	for _, issue := range board.issues {
+		board.stageCounts[board.getColumn(issue)]++
+	}
+
+	if board.swimlane == "" {
+		board.tableRows, err = board.generateRows(board.issues, activeIssueKey)
+
+		return err
+	}
+
+	swimlanes, err := board.getSwimlanes()
+	if err != nil {
+		return err
+	}
+
+	board.tableRows = [][]string{}
+	board.laneRows = map[int]bool{}
+	for _, swimlane := range swimlanes {
+		// issues which are not placed into columns are not counted, lanes
+		// without such issues are not shown
+		count := 0
+		for _, issue := range swimlane.issues {
+			if board.isShown(issue) {
+				count++
+			}
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		header := make([]string, len(board.tableHeaders))
+		if len(header) > 0 {
+			header[0] = escapeLoreley(fmt.Sprintf(
+				"» %s (%d)", swimlane.name, count,
+			))
+		}
+
+		rows, err := board.generateRows(swimlane.issues, activeIssueKey)
+		if err != nil {
+			return err
+		}
+
+		board.laneRows[len(board.tableRows)] = true
+		board.tableRows = append(board.tableRows, header)
+		board.tableRows = append(board.tableRows, rows...)
+	}
+
+	return nil
+}
+
+func (board *kanbanBoard) getSwimlanes() ([]swimlane, error) {
+	get := swimlaneGetters[board.swimlane]
+
+	defaultSwimlane := &swimlane{
+		name:  defaultSwimlaneNames[board.swimlane],
+		order: -1,
+	}
+
+	swimlanes := []*swimlane{}
+	swimlanesMap := map[string]*swimlane{}
 
 	for _, issue := range board.issues {
-		stage := issue.Fields.Status.Name
+		names, err := get(issue)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(names) == 0 {
+			defaultSwimlane.issues = append(defaultSwimlane.issues, issue)
+			continue
+		}
+
+		for _, name := range names {
+			lane, ok := swimlanesMap[name]
+			if !ok {
+				lane = &swimlane{name: name}
+
+				// priorities are ordered by importance, not by name
+				if board.swimlane == "priority" {
+					lane.order, _ = strconv.Atoi(issue.Extra.Priority.ID)
+				}
+
+				swimlanesMap[name] = lane
+				swimlanes = append(swimlanes, lane)
+			}
+
+			lane.issues = append(lane.issues, issue)
+		}
+	}
+
+	sort.SliceStable(swimlanes, func(i, j int) bool {
+		if swimlanes[i].order != swimlanes[j].order {
+			return swimlanes[i].order < swimlanes[j].order
+		}
+
+		return swimlanes[i].name < swimlanes[j].name
+	})
+
+	if len(defaultSwimlane.issues) > 0 {
+		swimlanes = append(swimlanes, defaultSwimlane)
+	}
+
+	result := []swimlane{}
+	for _, lane := range swimlanes {
+		result = append(result, *lane)
+	}
+
+	return result, nil
+}
+
+func (board *kanbanBoard) generateRows(
+	issues []Issue,
+	activeIssueKey string,
+) ([][]string, error) {
+	stageIssuesMap := map[string][]Issue{}
+
+	for _, issue := range issues {
+		stage := board.getColumn(issue)
 		if _, ok := stageIssuesMap[stage]; !ok {
-			stageIssuesMap[stage] = []gojira.Issue{}
+			stageIssuesMap[stage] = []Issue{}
 		}
 
 		stageIssuesMap[stage] = append(stageIssuesMap[stage], issue)
 	}
 
-	board.tableRows = [][]string{}
+	rows := [][]string{}
 	more := true
 	for rowIndex := 0; more; rowIndex++ {
 		more = false
-		board.tableRows = append(
-			board.tableRows,
+		rows = append(
+			rows,
 			make([]string, len(board.tableHeaders)),
 		)
 
 		for headerIndex, stage := range board.tableHeaders {
 			if len(stageIssuesMap[stage]) == 0 {
-				board.tableRows[rowIndex][headerIndex] = ""
+				rows[rowIndex][headerIndex] = ""
 				continue
 			}
 
@@ -89,43 +369,182 @@ func (board *kanbanBoard) GenerateBoardData(activeIssueKey string) {
 				more = true
 			}
 
-			issue := stageIssuesMap[stage][0]
+			item, err := board.renderCard(
+				stageIssuesMap[stage][0], activeIssueKey,
+			)
+			if err != nil {
+				return nil, err
+			}
 
-			item := issue.Key
+			rows[rowIndex][headerIndex] = item
 
-			if issue.Key == activeIssueKey {
-				item = "*" + item
-			}
+			stageIssuesMap[stage] = stageIssuesMap[stage][1:]
+		}
+	}
 
-			if board.showSummary {
-				item += " " + issue.Fields.Summary
-			}
+	return rows, nil
+}
 
-			if board.showName {
-				item += " (" + issue.Fields.Assignee.Name + ")"
-			}
+// getStatusStage returns stage which column shows issues with specified
+// status.
+func (board *kanbanBoard) getStatusStage(status string) (Stage, bool) {
+	for _, stage := range board.stages {
+		if stage.KanbanOrder != 0 && stage.HasStatus(status) {
+			return stage, true
+		}
+	}
 
-			board.tableRows[rowIndex][headerIndex] = item
+	return Stage{}, false
+}
 
-			stageIssuesMap[stage] = stageIssuesMap[stage][1:]
+func (board *kanbanBoard) isMapped(issue Issue) bool {
+	_, ok := board.getStatusStage(issue.Fields.Status.Name)
+
+	return ok
+}
+
+// getColumn returns name of the column where the issue is placed, empty
+// string means the issue is not shown on the board.
+func (board *kanbanBoard) getColumn(issue Issue) string {
+	stage, ok := board.getStatusStage(issue.Fields.Status.Name)
+
+	switch {
+	case ok:
+		return stage.Name
+	case board.unmapped == kanbanUnmappedColumn:
+		return kanbanOtherColumn
+	default:
+		return ""
+	}
+}
+
+// isShown returns true if issue is placed into one of board columns.
+func (board *kanbanBoard) isShown(issue Issue) bool {
+	column := board.getColumn(issue)
+	if column == "" {
+		return false
+	}
+
+	for _, header := range board.tableHeaders {
+		if header == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderCard renders issue card using stage template, result is loreley
+// styled text.
+func (board *kanbanBoard) renderCard(
+	issue Issue,
+	activeIssueKey string,
+) (string, error) {
+	view := getIssueListView(issue, activeIssueKey, board.showName)
+	for key, value := range view {
+		switch value := value.(type) {
+		case string:
+			view[key] = escapeLoreley(value)
+		case []string:
+			view[key] = escapeLoreleyAll(value)
+		}
+	}
+
+	if issue.Key == activeIssueKey {
+		view["mark"] = "*"
+	}
+
+	view["show_summary"] = board.showSummary
+	view["show_name"] = board.showName
+
+	tpl, ok := board.templates[board.getColumn(issue)]
+	if !ok {
+		tpl = board.templates[""]
+	}
+
+	card, err := tplutil.ExecuteToString(tpl, view)
+	if err != nil {
+		return "", karma.Format(
+			err,
+			"unable to execute template: %s", tpl.Name(),
+		)
+	}
+
+	if !board.isMapped(issue) {
+		card += " [" + escapeLoreley(issue.Fields.Status.Name) + "]"
+	}
+
+	return card, nil
+}
+
+// GetWIPViolations returns descriptions of columns which issue count is out
+// of stage WIP limits.
+func (board kanbanBoard) GetWIPViolations() []string {
+	violations := []string{}
+	for _, name := range board.tableHeaders {
+		stage, _ := board.getStage(name)
+		count := board.stageCounts[name]
+
+		switch board.getWIPState(name) {
+		case 1:
+			violations = append(violations, fmt.Sprintf(
+				"%s has %d issues, max is %d", name, count, stage.WIPLimit.Max,
+			))
+
+		case -1:
+			violations = append(violations, fmt.Sprintf(
+				"%s has %d issues, min is %d", name, count, stage.WIPLimit.Min,
+			))
 		}
 	}
+
+	return violations
 }
 
-func (board kanbanBoard) Display() {
-	table := tablewriter.NewWriter(os.Stdout)
-	if board.showSummary {
-		table.SetRowLine(true)
+func (board kanbanBoard) getStage(name string) (Stage, bool) {
+	for _, stage := range board.stages {
+		if stage.Name == name {
+			return stage, true
+		}
 	}
 
-	table.SetRowSeparator("─")
-	table.SetCenterSeparator("+")
-	table.SetColumnSeparator("│")
+	return Stage{}, false
+}
+
+// getWIPState returns 1 if issue count of the stage exceeds maximum WIP
+// limit, -1 if it is less than minimum and 0 otherwise.
+func (board kanbanBoard) getWIPState(name string) int {
+	stage, _ := board.getStage(name)
+	count := board.stageCounts[name]
+
+	switch {
+	case stage.WIPLimit.Max != 0 && count > stage.WIPLimit.Max:
+		return 1
+	case stage.WIPLimit.Min != 0 && count < stage.WIPLimit.Min:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// getHeaders returns column headers with issue counts and WIP limits.
+func (board kanbanBoard) getHeaders() []string {
+	headers := []string{}
+
+	for _, name := range board.tableHeaders {
+		stage, _ := board.getStage(name)
+
+		header := fmt.Sprintf("%s %d", name, board.stageCounts[name])
+		if stage.WIPLimit.Max != 0 {
+			header += fmt.Sprintf("/%d", stage.WIPLimit.Max)
+		}
+
+		if stage.WIPLimit.Min != 0 {
+			header += fmt.Sprintf(" (min %d)", stage.WIPLimit.Min)
+		}
 
-	table.SetHeader(board.tableHeaders)
-	for _, v := range board.tableRows {
-		table.Append(v)
+		headers = append(headers, header)
 	}
 
-	table.Render()
+	return headers
 }
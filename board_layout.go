@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/olekukonko/tablewriter"
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	kanbanLayoutAuto    = "auto"
+	kanbanLayoutTable   = "table"
+	kanbanLayoutStacked = "stacked"
+
+	// kanbanMinColumnWidth is a width of column which is enough to show
+	// issue key.
+	kanbanMinColumnWidth = 10
+)
+
+func (board kanbanBoard) Display() error {
+	width := getTerminalWidth()
+	headers := board.getHeaders()
+
+	columns := []int{}
+	for column := range board.tableHeaders {
+		columns = append(columns, column)
+	}
+
+	widths, fits := board.getColumnWidths(headers, columns, width)
+
+	collapsed := []string{}
+	if !fits {
+		columns = []int{}
+		for column, name := range board.tableHeaders {
+			if board.stageCounts[name] == 0 {
+				collapsed = append(collapsed, name)
+				continue
+			}
+
+			columns = append(columns, column)
+		}
+
+		widths, fits = board.getColumnWidths(headers, columns, width)
+	}
+
+	if board.layout == kanbanLayoutStacked ||
+		(board.layout == kanbanLayoutAuto && !fits) {
+		err := board.displayStacked(headers, width)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := board.displayTable(headers, columns, widths)
+		if err != nil {
+			return err
+		}
+
+		if len(collapsed) > 0 {
+			fmt.Printf("Empty columns: %s\n", strings.Join(collapsed, ", "))
+		}
+	}
+
+	if board.unmapped == kanbanUnmappedFooter {
+		board.displayUnmapped()
+	}
+
+	return nil
+}
+
+// displayUnmapped lists issues which statuses have no columns on the board
+// grouped by status.
+func (board kanbanBoard) displayUnmapped() {
+	statuses := []string{}
+	keys := map[string][]string{}
+
+	for _, issue := range board.unmappedIssues {
+		status := issue.Fields.Status.Name
+		if _, ok := keys[status]; !ok {
+			statuses = append(statuses, status)
+		}
+
+		keys[status] = append(keys[status], issue.Key)
+	}
+
+	if len(statuses) == 0 {
+		return
+	}
+
+	fmt.Println("Not on the board:")
+	for _, status := range statuses {
+		fmt.Printf("  %s: %s\n", status, strings.Join(keys[status], ", "))
+	}
+}
+
+func (board kanbanBoard) displayTable(
+	headers []string,
+	columns []int,
+	widths map[int]int,
+) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	if board.showSummary {
+		table.SetRowLine(true)
+	}
+
+	table.SetAutoWrapText(false)
+	table.SetRowSeparator("─")
+	table.SetCenterSeparator("+")
+	table.SetColumnSeparator("│")
+
+	visibleHeaders := []string{}
+	colors := []tablewriter.Colors{}
+	for _, column := range columns {
+		visibleHeaders = append(visibleHeaders, headers[column])
+
+		switch board.getWIPState(board.tableHeaders[column]) {
+		case 1:
+			colors = append(
+				colors,
+				tablewriter.Colors{tablewriter.Bold, tablewriter.FgRedColor},
+			)
+		case -1:
+			colors = append(colors, tablewriter.Colors{tablewriter.FgYellowColor})
+		default:
+			colors = append(colors, tablewriter.Colors{})
+		}
+	}
+
+	table.SetHeader(visibleHeaders)
+
+	if isColorized() {
+		table.SetHeaderColor(colors...)
+	}
+
+	for rowIndex, row := range board.tableRows {
+		cells := []string{}
+		for index, column := range columns {
+			cell := row[column]
+
+			// swimlane name is always placed in the first visible column
+			if board.laneRows[rowIndex] {
+				cell = ""
+				if index == 0 {
+					cell = row[0]
+				}
+			}
+
+			cell, err := colorize(board.fitCell(cell, widths[column]))
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to colorize kanban card",
+				)
+			}
+
+			cells = append(cells, cell)
+		}
+
+		table.Append(cells)
+	}
+
+	table.Render()
+
+	return nil
+}
+
+// displayStacked displays board columns one after another, it is used when
+// board can't fit terminal horizontally.
+func (board kanbanBoard) displayStacked(headers []string, width int) error {
+	buffer := &strings.Builder{}
+
+	for column, name := range board.tableHeaders {
+		style := "<bold>"
+		switch board.getWIPState(name) {
+		case 1:
+			style += "<fg 1>"
+		case -1:
+			style += "<fg 3>"
+		}
+
+		buffer.WriteString(style + escapeLoreley(headers[column]) + "<reset>\n")
+
+		lane := ""
+		for rowIndex, row := range board.tableRows {
+			if board.laneRows[rowIndex] {
+				lane = row[0]
+				continue
+			}
+
+			if row[column] == "" {
+				continue
+			}
+
+			indent := "  "
+			if lane != "" {
+				buffer.WriteString("  " + lane + "\n")
+				lane = ""
+			}
+
+			if board.swimlane != "" {
+				indent = "    "
+			}
+
+			cell := board.fitCell(row[column], width-len(indent))
+			for _, line := range strings.Split(cell, "\n") {
+				buffer.WriteString(indent + line + "<reset>\n")
+			}
+		}
+
+		buffer.WriteString("\n")
+	}
+
+	result, err := colorize(buffer.String())
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to colorize kanban board",
+		)
+	}
+
+	fmt.Print(result)
+
+	return nil
+}
+
+// getColumnWidths returns widths for specified columns which fit the given
+// total width of the table. Columns which need less space than the fair share
+// keep their width and the rest of the space is shared equally. It returns
+// false if some columns can't fit their header or issue key.
+func (board kanbanBoard) getColumnWidths(
+	headers []string,
+	columns []int,
+	total int,
+) (map[int]int, bool) {
+	natural := map[int]int{}
+	minimal := map[int]int{}
+
+	for _, column := range columns {
+		minimal[column] = kanbanMinColumnWidth
+		if width := runewidth.StringWidth(headers[column]); width > minimal[column] {
+			minimal[column] = width
+		}
+
+		natural[column] = minimal[column]
+		for _, row := range board.tableRows {
+			if width := getVisibleWidth(row[column]); width > natural[column] {
+				natural[column] = width
+			}
+		}
+	}
+
+	// every column takes 3 symbols for padding and separator plus one
+	// symbol for the right border
+	available := total - 1 - 3*len(columns)
+
+	sorted := append([]int{}, columns...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return natural[sorted[i]] < natural[sorted[j]]
+	})
+
+	widths := map[int]int{}
+	fits := true
+	for index, column := range sorted {
+		share := available / (len(sorted) - index)
+
+		widths[column] = natural[column]
+		if widths[column] > share {
+			widths[column] = share
+		}
+
+		if widths[column] < minimal[column] {
+			widths[column] = minimal[column]
+			fits = false
+		}
+
+		available -= widths[column]
+	}
+
+	return widths, fits
+}
+
+// fitCell truncates or wraps loreley styled cell contents to specified
+// width.
+func (board kanbanBoard) fitCell(cell string, width int) string {
+	if getVisibleWidth(cell) <= width {
+		return cell
+	}
+
+	if !board.wrap {
+		return truncateStyles(cell, width)
+	}
+
+	lines := []string{}
+	line := ""
+	for _, word := range splitStyledWords(cell) {
+		if word == "" {
+			continue
+		}
+
+		if getVisibleWidth(word) > width {
+			word = truncateStyles(word, width)
+		}
+
+		if line != "" && getVisibleWidth(line+" "+word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+
+		line += word
+	}
+
+	lines = append(lines, line)
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/reconquest/karma-go"
+	"github.com/tears-of-noobs/gojira"
+)
+
+// bulkAction is a change which is applied to every issue of bulk operation.
+type bulkAction struct {
+	description string
+	apply       func(issue *Issue) error
+}
+
+// getBulkAction returns action of bulk command specified by arguments.
+func getBulkAction(
+	args map[string]interface{},
+	config *Configuration,
+) (bulkAction, error) {
+	switch {
+	case args["assign"].(bool):
+		user := args["<user>"].(string)
+
+		return bulkAction{
+			description: "assign to " + user,
+			apply: func(issue *Issue) error {
+				return issue.Assignee(user)
+			},
+		}, nil
+
+	case args["move"].(bool):
+		transition := args["<transition>"].(string)
+
+		// required fields can't be asked for many issues at once
+		options, err := getTransitionOptions(args)
+		if err != nil {
+			return bulkAction{}, err
+		}
+
+		options.prompt = false
+
+		return bulkAction{
+			description: "move to " + transition,
+			apply: func(issue *Issue) error {
+				_, err := transitionIssue(
+					issue, transition, config.Workflow, options,
+				)
+				return err
+			},
+		}, nil
+
+	case args["set"].(bool):
+		var (
+			field = args["<field>"].(string)
+			value = args["<value>"].(string)
+		)
+
+		return bulkAction{
+			description: fmt.Sprintf("set %s to %s", field, value),
+			apply: func(issue *Issue) error {
+				return setIssueField(issue.Key, field, value)
+			},
+		}, nil
+
+	case args["label"].(bool):
+		// labels are added unless they are prefixed with + or -
+		labels := []string{}
+		for _, label := range splitList(args["<labels>"].(string)) {
+			if !strings.HasPrefix(label, "+") && !strings.HasPrefix(label, "-") {
+				label = "+" + label
+			}
+
+			labels = append(labels, label)
+		}
+
+		value := strings.Join(labels, ",")
+
+		return bulkAction{
+			description: "change labels " + value,
+			apply: func(issue *Issue) error {
+				return setIssueField(issue.Key, "labels", value)
+			},
+		}, nil
+
+	case args["comment"].(bool):
+		text := args["<text>"].(string)
+
+		return bulkAction{
+			description: "comment",
+			apply: func(issue *Issue) error {
+				_, err := issue.SetComment(&gojira.Comment{Body: text})
+				return err
+			},
+		}, nil
+
+	case args["rename"].(bool):
+		title := args["<title>"].(string)
+
+		// the same expression is applied to every issue, so it's checked
+		// before confirmation
+		substitution, err := parseRenameSubstitution(title)
+		if err != nil {
+			return bulkAction{}, err
+		}
+
+		return getRenameAction(substitution, title), nil
+	}
+
+	return bulkAction{}, errors.New("unknown bulk action")
+}
+
+// bulkFailure is an issue key read from stdin which can't be resolved to
+// issue.
+type bulkFailure struct {
+	key string
+	err error
+}
+
+var reIssueKey = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// getBulkIssues returns all issues matching JQL or issues which keys are
+// read from stdin if JQL is empty, keys which can't be resolved to issues
+// are returned as failures.
+func getBulkIssues(
+	jql string,
+	project string,
+) ([]Issue, []bulkFailure, error) {
+	if jql != "" {
+		issues, err := getAllIssues(jql)
+		if err != nil {
+			return nil, nil, karma.Format(
+				err,
+				"unable to search issues: %s", jql,
+			)
+		}
+
+		return issues, nil, nil
+	}
+
+	stdin, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, nil, karma.Format(
+			err,
+			"unable to read issue keys from stdin",
+		)
+	}
+
+	tokens := strings.Fields(string(stdin))
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("no issue keys are specified in stdin")
+	}
+
+	var (
+		issues   = []Issue{}
+		failures = []bulkFailure{}
+	)
+
+	for _, token := range tokens {
+		key := strings.ToUpper(getIssueKey(token, project))
+		if !reIssueKey.MatchString(key) {
+			failures = append(
+				failures,
+				bulkFailure{token, errors.New("invalid issue key")},
+			)
+			continue
+		}
+
+		issue, err := getIssue(key)
+		if err != nil {
+			failures = append(failures, bulkFailure{key, err})
+			continue
+		}
+
+		issues = append(issues, *issue)
+	}
+
+	return issues, failures, nil
+}
+
+// handleBulkMode applies action to all specified issues after confirmation,
+// actions are applied concurrently by specified amount of jobs.
+func handleBulkMode(
+	issues []Issue,
+	failures []bulkFailure,
+	action bulkAction,
+	config *Configuration,
+	jobs int,
+	confirmed bool,
+) error {
+	for _, failure := range failures {
+		fmt.Fprintf(os.Stderr, "%s: failed: %s\n", failure.key, failure.err)
+	}
+
+	total := len(issues) + len(failures)
+
+	if len(issues) == 0 {
+		if len(failures) > 0 {
+			return fmt.Errorf("%d of %d issues are failed", total, total)
+		}
+
+		fmt.Println("No issues found")
+		return nil
+	}
+
+	// issues of hidden stages are changed too, so they are listed
+	workflow := config.Workflow
+	workflow.Stages = []Stage{}
+	for _, stage := range config.Workflow.Stages {
+		if stage.Order == -1 {
+			stage.Order = 0
+		}
+
+		workflow.Stages = append(workflow.Stages, stage)
+	}
+
+	err := displayIssues(issues, "", false, false, workflow, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		ok, err := confirm(
+			fmt.Sprintf(
+				"Apply %s to %d issues? [y/N] ",
+				action.description, len(issues),
+			),
+		)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			log.Println("Aborted")
+			return nil
+		}
+	}
+
+	var (
+		errs      = make([]error, len(issues))
+		semaphore = make(chan struct{}, jobs)
+		group     sync.WaitGroup
+	)
+
+	for i := range issues {
+		group.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				group.Done()
+			}()
+
+			errs[i] = action.apply(&issues[i])
+		}(i)
+	}
+
+	group.Wait()
+
+	failed := len(failures)
+	for i, issue := range issues {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "%s: failed: %s\n", issue.Key, errs[i])
+			failed++
+			continue
+		}
+
+		fmt.Printf("%s: done\n", issue.Key)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d issues are failed", failed, total)
+	}
+
+	return nil
+}
+
+func getBulkJobs(args map[string]interface{}) (int, error) {
+	rawJobs, _ := args["--jobs"].(string)
+
+	jobs, err := strconv.Atoi(rawJobs)
+	if err != nil || jobs < 1 {
+		return 0, fmt.Errorf("invalid amount of jobs: %s", rawJobs)
+	}
+
+	return jobs, nil
+}
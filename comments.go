@@ -2,7 +2,7 @@ package main
 
 import "github.com/tears-of-noobs/gojira"
 
-func addComment(issue *gojira.Issue) error {
+func addComment(issue *Issue) error {
 	comment, err := editTemporaryFile("", issue.Key+".batrak")
 	if err != nil {
 		return err
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
@@ -15,16 +16,91 @@ type Configuration struct {
 	Workflow    Workflow            `toml:"workflow"`
 	Hooks       map[string][]string `toml:"hooks"`
 	Filter      int                 `toml:"filter_id"`
+
+	// Board is an identifier of Jira Software board which is used by sprint
+	// and backlog commands if --board is not specified.
+	Board int `toml:"board_id"`
+
+	// IssueTemplate overrides template of issue view.
+	IssueTemplate string `toml:"issue_template"`
+
+	// CreateTemplates pre-fill editor buffer for new issues.
+	CreateTemplates []CreateTemplate `toml:"create_template"`
+}
+
+// CreateTemplate is a description skeleton with $field: lines which is used
+// for new issues of specified project and issue type, empty project or issue
+// type matches any.
+type CreateTemplate struct {
+	Name      string `toml:"name"`
+	Project   string `toml:"project"`
+	IssueType string `toml:"issue_type"`
+	Template  string `toml:"template"`
+}
+
+// GetCreateTemplate returns template with specified name or the most
+// specific template which matches project and issue type if name is empty.
+func (config *Configuration) GetCreateTemplate(
+	name string,
+	project string,
+	issueType string,
+) (CreateTemplate, bool) {
+	var (
+		result CreateTemplate
+		found  bool
+		best   int
+	)
+
+	for _, template := range config.CreateTemplates {
+		if name != "" {
+			if template.Name == name {
+				return template, true
+			}
+
+			continue
+		}
+
+		score := 0
+
+		switch {
+		case template.Project == "":
+		case strings.EqualFold(template.Project, project):
+			score += 2
+		default:
+			continue
+		}
+
+		switch {
+		case template.IssueType == "":
+		case strings.EqualFold(template.IssueType, issueType):
+			score++
+		default:
+			continue
+		}
+
+		if !found || score > best {
+			result = template
+			found = true
+			best = score
+		}
+	}
+
+	return result, found
 }
 
 type Workflow struct {
-	AgileFields []string `toml:"agile_fields"`
-	Stages      []Stage  `toml:"stage"`
+	AgileFields    []string `toml:"agile_fields"`
+	Stages         []Stage  `toml:"stage"`
+	KanbanTemplate string   `toml:"kanban_template"`
+
+	// KanbanUnmapped specifies how to show issues which statuses have no
+	// columns on kanban board: column, footer or hide.
+	KanbanUnmapped string `toml:"kanban_unmapped"`
 }
 
 func (workflow *Workflow) GetStage(name string) (Stage, bool) {
 	for _, stage := range workflow.Stages {
-		if stage.Name == name {
+		if stage.HasStatus(name) {
 			return stage, true
 		}
 	}
@@ -33,10 +109,41 @@ func (workflow *Workflow) GetStage(name string) (Stage, bool) {
 }
 
 type Stage struct {
-	Name        string `toml:"name"`
-	Order       int    `toml:"order"`
-	KanbanOrder int    `toml:"kanban_order"`
-	Template    string `toml:"template"`
+	Name        string   `toml:"name"`
+	Order       int      `toml:"order"`
+	KanbanOrder int      `toml:"kanban_order"`
+	Template    string   `toml:"template"`
+	WIPLimit    WIPLimit `toml:"wip_limit"`
+
+	// KanbanTemplate overrides template of issue card on kanban board.
+	KanbanTemplate string `toml:"kanban_template"`
+
+	// Statuses are names of statuses which issues are shown in the stage
+	// column on kanban board, stage name is used if it is empty.
+	Statuses []string `toml:"statuses"`
+}
+
+// HasStatus returns true if issues with specified status belong to the
+// stage.
+func (stage Stage) HasStatus(status string) bool {
+	if len(stage.Statuses) == 0 {
+		return stage.Name == status
+	}
+
+	for _, name := range stage.Statuses {
+		if name == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WIPLimit is a limit of work in progress for kanban board column, zero
+// value means no limit.
+type WIPLimit struct {
+	Min int `toml:"min"`
+	Max int `toml:"max"`
 }
 
 func getConfig(filePath string) (*Configuration, error) {
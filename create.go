@@ -1,28 +1,131 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"reflect"
 	"strings"
 
 	"github.com/reconquest/executil-go"
 	"github.com/reconquest/karma-go"
-	"github.com/tears-of-noobs/gojira"
 	"github.com/zazab/zhash"
 )
 
 const (
+	// commentPrefix marks lines of editor buffer which are not part of
+	// issue, lines starting with # are numbered lists in Jira markup.
+	commentPrefix = "#!"
+
 	prefaceCreateIssue = `
 
-# Write a summary & description for this issue.
-# The first line of text is the summary and the rest is description.
+#! Write a summary & description for this issue.
+#! The first line of text is the summary and the rest is description.
 `
 )
 
-func handleCreateMode(project, issueType string) error {
-	contents, err := editTemporaryFile(prefaceCreateIssue, ".batrak")
+// createOptions are values of new issue which are specified by flags,
+// editor is not used if summary is specified.
+type createOptions struct {
+	summary     string
+	description string
+	json        bool
+
+	// rawFields are fields which values are converted to field types like
+	// values of $field: editor lines.
+	rawFields map[string]string
+
+	// fields are values of fields which are set as is.
+	fields map[string]interface{}
+}
+
+type createdIssue struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Self string `json:"self"`
+}
+
+// handleCreateMode creates issue using summary, description and fields
+// written in editor or specified by flags, issue is created as subtask if
+// parent key is not empty. Editor buffer is pre-filled with specified
+// description and template contents.
+func handleCreateMode(
+	project, issueType, parentKey string,
+	template string,
+	options createOptions,
+) error {
+	if options.summary == "" {
+		// stdin is used by editor, so description can't be read from it
+		if options.description == "-" {
+			return errors.New(
+				"summary (--summary) should be specified to read " +
+					"description from stdin",
+			)
+		}
+
+		body := []string{}
+		for _, chunk := range []string{options.description, template} {
+			if strings.TrimSpace(chunk) != "" {
+				body = append(body, strings.TrimSpace(chunk))
+			}
+		}
+
+		preface := prefaceCreateIssue
+		if len(body) > 0 {
+			preface = "\n\n" + strings.Join(body, "\n\n") + prefaceCreateIssue
+		}
+
+		return createIssueInEditor(
+			&draft{
+				Project:   project,
+				IssueType: issueType,
+				ParentKey: parentKey,
+			},
+			preface,
+			options,
+		)
+	}
+
+	desc := options.description
+	if desc == "-" {
+		stdin, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to read description from stdin",
+			)
+		}
+
+		desc = strings.TrimRight(string(stdin), "\n")
+	}
+
+	issue, err := createIssue(
+		project, issueType, parentKey,
+		options.summary, desc, map[string]string{},
+		options,
+	)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to create issue",
+		)
+	}
+
+	return printCreatedIssue(issue, options.json)
+}
+
+// createIssueInEditor creates issue using contents written in editor, the
+// contents are saved as draft if issue can't be created. Draft is removed
+// when issue is created.
+func createIssueInEditor(
+	draft *draft,
+	preface string,
+	options createOptions,
+) error {
+	contents, err := editTemporaryFile(preface, ".batrak")
 	if err != nil {
 		if executil.IsExitError(err) {
 			return nil
@@ -31,9 +134,134 @@ func handleCreateMode(project, issueType string) error {
 		return err
 	}
 
+	summary, desc, fields, ok := parseIssueBuffer(contents)
+	if !ok || (draft.path == "" && isBufferUnchanged(contents, preface)) {
+		log.Println("Aborted")
+		return nil
+	}
+
+	issue, err := createIssue(
+		draft.Project, draft.IssueType, draft.ParentKey,
+		summary, desc, fields,
+		options,
+	)
+	if err != nil {
+		draft.Errors = getErrorLines(err)
+
+		draftErr := saveDraft(draft, contents)
+		if draftErr != nil {
+			return karma.Format(
+				err,
+				"unable to create issue and to save draft: %s", draftErr,
+			)
+		}
+
+		fmt.Fprintf(
+			os.Stderr,
+			"Draft is saved to %s, use -N --last-draft to edit it\n",
+			draft.path,
+		)
+
+		return karma.Format(
+			err,
+			"unable to create issue",
+		)
+	}
+
+	if draft.path != "" {
+		err = removeDraft(draft)
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to remove draft of created issue %s", issue.Key,
+			)
+		}
+	}
+
+	return printCreatedIssue(issue, options.json)
+}
+
+func createIssue(
+	project, issueType, parentKey string,
+	summary, desc string,
+	fields map[string]string,
+	options createOptions,
+) (*createdIssue, error) {
+	hash := zhash.NewHash()
+	hash.Set(project, "fields", "project", "key")
+	hash.Set(summary, "fields", "summary")
+	hash.Set(desc, "fields", "description")
+	hash.Set(issueType, "fields", "issuetype", "name")
+	if parentKey != "" {
+		hash.Set(parentKey, "fields", "parent", "key")
+	}
+
+	for key, value := range options.rawFields {
+		fields[key] = value
+	}
+
+	if len(fields) > 0 {
+		// values are sent as is if fields are not known
+		metaFields, err := getCreateMetaFields(project, issueType)
+		if err != nil {
+			log.Println(
+				karma.Format(
+					err,
+					"unable to get fields of issue type %s in project %s, "+
+						"field values are not converted",
+					issueType, project,
+				),
+			)
+		}
+
+		coerced, err := coerceFields(fields, metaFields)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range coerced {
+			hash.Set(value, append([]string{"fields"}, strings.Split(key, ".")...)...)
+		}
+	}
+
+	for key, value := range options.fields {
+		hash.Set(value, append([]string{"fields"}, strings.Split(key, ".")...)...)
+	}
+
+	var issue createdIssue
+	err := requestAPI("POST", "/issue", hash.GetRoot(), &issue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issue, nil
+}
+
+func printCreatedIssue(issue *createdIssue, printJSON bool) error {
+	if printJSON {
+		encoded, err := json.Marshal(issue)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(encoded))
+
+		return nil
+	}
+
+	fmt.Println(issue.Key)
+
+	return nil
+}
+
+// parseIssueBuffer returns summary, description and $field: lines written
+// in editor, it returns false if nothing is written.
+func parseIssueBuffer(
+	contents string,
+) (string, string, map[string]string, bool) {
 	var summary, desc []string
 	for _, line := range strings.Split(contents, "\n") {
-		if strings.HasPrefix(line, "#") {
+		if strings.HasPrefix(line, commentPrefix) {
 			continue
 		}
 
@@ -52,20 +280,15 @@ func handleCreateMode(project, issueType string) error {
 	}
 
 	if len(summary) == 0 && len(desc) == 0 {
-		log.Println("Aborted")
-		return nil
+		return "", "", nil, false
 	}
 
+	// buffer usually ends with empty lines which are followed by comments
+	desc = trimEmptyLines(desc)
+
 	fields := map[string]string{}
 	i := len(desc) - 1
-	for ; i > 0; i-- {
-		if desc[i] == "" {
-			if i == len(desc)-1 {
-				continue
-			}
-
-			break
-		}
+	for ; i >= 0; i-- {
 		if !strings.HasPrefix(desc[i], "$") {
 			break
 		}
@@ -77,32 +300,93 @@ func handleCreateMode(project, issueType string) error {
 		fields[chunks[0][1:]] = chunks[1]
 	}
 
-	desc = desc[:i+1]
+	desc = trimEmptyLines(desc[:i+1])
 
-	hash := zhash.NewHash()
-	hash.Set(project, "fields", "project", "key")
-	hash.Set(strings.Join(summary, "\n"), "fields", "summary")
-	hash.Set(strings.Join(desc, "\n"), "fields", "description")
-	hash.Set(issueType, "fields", "issuetype", "name")
-	for key, value := range fields {
-		hash.Set(value, append([]string{"fields"}, strings.Split(key, ".")...)...)
+	return strings.Join(summary, "\n"), strings.Join(desc, "\n"), fields, true
+}
+
+// isBufferUnchanged returns true if contents written in editor are the same
+// as initial contents like create template, comments and empty lines are not
+// taken into account.
+func isBufferUnchanged(contents string, initial string) bool {
+	summary, desc, fields, ok := parseIssueBuffer(contents)
+	initialSummary, initialDesc, initialFields, initialOk := parseIssueBuffer(
+		initial,
+	)
+
+	return ok == initialOk &&
+		summary == initialSummary &&
+		desc == initialDesc &&
+		reflect.DeepEqual(fields, initialFields)
+}
+
+// trimEmptyLines removes trailing empty lines.
+func trimEmptyLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
 	}
 
-	buffer := new(bytes.Buffer)
-	err = json.NewEncoder(buffer).Encode(hash.GetRoot())
-	if err != nil {
-		return err
+	return lines
+}
+
+// getCreateRawFields returns issue fields specified by --field key=value
+// flags.
+func getCreateRawFields(args map[string]interface{}) (map[string]string, error) {
+	fields := map[string]string{}
+
+	rawFields, _ := args["--field"].([]string)
+	for _, rawField := range rawFields {
+		chunks := strings.SplitN(rawField, "=", 2)
+		if len(chunks) != 2 || strings.TrimSpace(chunks[0]) == "" {
+			return nil, fmt.Errorf(
+				"invalid field: %s, expected key=value", rawField,
+			)
+		}
+
+		fields[strings.TrimSpace(chunks[0])] = chunks[1]
 	}
 
-	issue, err := gojira.CreateIssue(buffer)
-	if err != nil {
-		return karma.Format(
-			err,
-			"unable to create issue",
-		)
+	return fields, nil
+}
+
+// getCreateFields returns issue fields specified by shortcut flags like
+// --label.
+func getCreateFields(args map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+
+	if labels, ok := args["--label"].(string); ok {
+		fields["labels"] = splitList(labels)
 	}
 
-	fmt.Println(issue.Key)
+	if components, ok := args["--component"].(string); ok {
+		values := []map[string]string{}
+		for _, name := range splitList(components) {
+			values = append(values, map[string]string{"name": name})
+		}
 
-	return nil
+		fields["components"] = values
+	}
+
+	if assignee, ok := args["--assignee"].(string); ok {
+		fields["assignee"] = map[string]string{"name": assignee}
+	}
+
+	if priority, ok := args["--priority"].(string); ok {
+		fields["priority"] = map[string]string{"name": priority}
+	}
+
+	return fields
+}
+
+// splitList splits comma-separated list and trims its values.
+func splitList(list string) []string {
+	values := []string{}
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
 }
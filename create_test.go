@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIssueBuffer(t *testing.T) {
+	testcases := []struct {
+		contents string
+		summary  string
+		desc     string
+		fields   map[string]string
+		ok       bool
+	}{
+		{
+			contents: "",
+			ok:       false,
+		},
+		{
+			contents: "\n\n" + commentPrefix + " comment\n",
+			ok:       false,
+		},
+		{
+			contents: "Title",
+			summary:  "Title",
+			fields:   map[string]string{},
+			ok:       true,
+		},
+		{
+			contents: "Title\n\nSteps:\n# one\n# two",
+			summary:  "Title",
+			desc:     "Steps:\n# one\n# two",
+			fields:   map[string]string{},
+			ok:       true,
+		},
+		{
+			contents: "Title\n\nText\n\n$labels: a, b\n$Story Points: 3\n\n\n" +
+				prefaceCreateIssue,
+			summary: "Title",
+			desc:    "Text",
+			fields: map[string]string{
+				"labels":       " a, b",
+				"Story Points": " 3",
+			},
+			ok: true,
+		},
+		{
+			contents: draftErrorPrefix + "error\nTitle\n\n$labels: a",
+			summary:  "Title",
+			fields:   map[string]string{"labels": " a"},
+			ok:       true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		summary, desc, fields, ok := parseIssueBuffer(testcase.contents)
+		if ok != testcase.ok {
+			t.Errorf("%q: expected ok %v, got %v", testcase.contents, testcase.ok, ok)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		if summary != testcase.summary {
+			t.Errorf(
+				"%q: expected summary %q, got %q",
+				testcase.contents, testcase.summary, summary,
+			)
+		}
+
+		if desc != testcase.desc {
+			t.Errorf(
+				"%q: expected description %q, got %q",
+				testcase.contents, testcase.desc, desc,
+			)
+		}
+
+		if !reflect.DeepEqual(fields, testcase.fields) {
+			t.Errorf(
+				"%q: expected fields %v, got %v",
+				testcase.contents, testcase.fields, fields,
+			)
+		}
+	}
+}
+
+func TestIsBufferUnchanged(t *testing.T) {
+	template := "\n\nh3. Steps\n\n$labels: bug" + prefaceCreateIssue
+
+	testcases := []struct {
+		contents  string
+		unchanged bool
+	}{
+		{template, true},
+		{template + "\n\n", true},
+		{"\n\nh3. Steps\n\n$labels: bug\n", true},
+		{"Crash on start\n\nh3. Steps\n\n$labels: bug" + prefaceCreateIssue, false},
+		{"\n\nh3. Steps\nopen app\n\n$labels: bug", false},
+		{"\n\nh3. Steps\n\n$labels: bug, ui", false},
+	}
+
+	for _, testcase := range testcases {
+		unchanged := isBufferUnchanged(testcase.contents, template)
+		if unchanged != testcase.unchanged {
+			t.Errorf(
+				"%q: expected unchanged %v, got %v",
+				testcase.contents, testcase.unchanged, unchanged,
+			)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+type createMeta struct {
+	Projects []struct {
+		Key        string `json:"key"`
+		IssueTypes []struct {
+			Name   string               `json:"name"`
+			Fields map[string]metaField `json:"fields"`
+		} `json:"issuetypes"`
+	} `json:"projects"`
+}
+
+type metaField struct {
+	Name            string         `json:"name"`
+	Schema          fieldSchema    `json:"schema"`
+	Required        bool           `json:"required"`
+	HasDefaultValue bool           `json:"hasDefaultValue"`
+	AllowedValues   []allowedValue `json:"allowedValues"`
+}
+
+// allowedValue is a value of select field, it has either name or value.
+type allowedValue struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// createMetaPage is a page of issue types or fields returned by create
+// metadata endpoints of issue type, Jira Data Center returns them as values
+// while Jira Cloud uses issueTypes and fields.
+type createMetaPage struct {
+	Total      int             `json:"total"`
+	IsLast     bool            `json:"isLast"`
+	Values     json.RawMessage `json:"values"`
+	IssueTypes json.RawMessage `json:"issueTypes"`
+	Fields     json.RawMessage `json:"fields"`
+}
+
+func (page createMetaPage) getValues() json.RawMessage {
+	switch {
+	case len(page.IssueTypes) > 0:
+		return page.IssueTypes
+	case len(page.Fields) > 0:
+		return page.Fields
+	default:
+		return page.Values
+	}
+}
+
+type createMetaIssueType struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type createMetaField struct {
+	FieldID string `json:"fieldId"`
+	metaField
+}
+
+// getCreateMetaFields returns fields which can be set on issue creation
+// by field identifiers. Create metadata of issue type is used if Jira
+// doesn't provide create metadata of projects, like Jira Data Center 9.
+func getCreateMetaFields(
+	project string,
+	issueType string,
+) (map[string]metaField, error) {
+	fields, err := getProjectCreateMetaFields(project, issueType)
+	if err == nil {
+		return fields, nil
+	}
+
+	fields, typeErr := getIssueTypeCreateMetaFields(project, issueType)
+	if typeErr != nil {
+		return nil, karma.Format(
+			typeErr,
+			"create metadata of project is not available: %s", err,
+		)
+	}
+
+	return fields, nil
+}
+
+func getProjectCreateMetaFields(
+	project string,
+	issueType string,
+) (map[string]metaField, error) {
+	var meta createMeta
+	err := requestAPI(
+		"GET",
+		"/issue/createmeta"+
+			"?projectKeys="+url.QueryEscape(project)+
+			"&issuetypeNames="+url.QueryEscape(issueType)+
+			"&expand=projects.issuetypes.fields",
+		nil, &meta,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, metaProject := range meta.Projects {
+		for _, metaIssueType := range metaProject.IssueTypes {
+			if strings.EqualFold(metaIssueType.Name, issueType) {
+				return metaIssueType.Fields, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"issue type %s is not available in project %s", issueType, project,
+	)
+}
+
+func getIssueTypeCreateMetaFields(
+	project string,
+	issueType string,
+) (map[string]metaField, error) {
+	path := "/issue/createmeta/" + url.PathEscape(project) + "/issuetypes"
+
+	issueTypes := []createMetaIssueType{}
+	err := getCreateMetaPages(path, func(values json.RawMessage) (int, error) {
+		var page []createMetaIssueType
+		err := json.Unmarshal(values, &page)
+		issueTypes = append(issueTypes, page...)
+
+		return len(page), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, metaIssueType := range issueTypes {
+		if !strings.EqualFold(metaIssueType.Name, issueType) {
+			continue
+		}
+
+		fields := map[string]metaField{}
+		err := getCreateMetaPages(
+			path+"/"+url.PathEscape(metaIssueType.ID),
+			func(values json.RawMessage) (int, error) {
+				var page []createMetaField
+				err := json.Unmarshal(values, &page)
+				for _, field := range page {
+					fields[field.FieldID] = field.metaField
+				}
+
+				return len(page), err
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return fields, nil
+	}
+
+	return nil, fmt.Errorf(
+		"issue type %s is not available in project %s", issueType, project,
+	)
+}
+
+// getCreateMetaPages requests all pages of create metadata endpoint, values
+// of every page are passed to decode which returns amount of values.
+func getCreateMetaPages(
+	path string,
+	decode func(values json.RawMessage) (int, error),
+) error {
+	count := 0
+	for {
+		var page createMetaPage
+		err := requestAPI(
+			"GET", path+"?startAt="+strconv.Itoa(count), nil, &page,
+		)
+		if err != nil {
+			return err
+		}
+
+		values := page.getValues()
+		if len(values) == 0 {
+			return nil
+		}
+
+		size, err := decode(values)
+		if err != nil {
+			return err
+		}
+
+		count += size
+
+		if page.IsLast || size == 0 || count >= page.Total {
+			return nil
+		}
+	}
+}
+
+// coerceFields converts string values of fields specified by user to
+// values of field types, like comma-separated lists to arrays and names to
+// objects. Fields can be specified by identifiers or by names. Fields with
+// dotted paths and unknown fields are left as is.
+func coerceFields(
+	fields map[string]string,
+	metaFields map[string]metaField,
+) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+
+	for key, value := range fields {
+		value = strings.TrimSpace(value)
+
+		if strings.Contains(key, ".") {
+			result[key] = value
+			continue
+		}
+
+		id, metaField, ok := findMetaField(key, metaFields)
+		if !ok {
+			result[key] = value
+			continue
+		}
+
+		coerced, err := coerceFieldValue(metaField.Schema, value)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid value of field %s: %s", metaField.Name, err,
+			)
+		}
+
+		result[id] = coerced
+	}
+
+	return result, nil
+}
+
+func findMetaField(
+	key string,
+	metaFields map[string]metaField,
+) (string, metaField, bool) {
+	if field, ok := metaFields[key]; ok {
+		return key, field, true
+	}
+
+	for id, field := range metaFields {
+		if strings.EqualFold(field.Name, key) {
+			return id, field, true
+		}
+	}
+
+	return "", metaField{}, false
+}
+
+func coerceFieldValue(schema fieldSchema, value string) (interface{}, error) {
+	if schema.Type == "array" {
+		values := []interface{}{}
+		for _, item := range splitList(value) {
+			coerced, err := coerceFieldValue(fieldSchema{Type: schema.Items}, item)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, coerced)
+		}
+
+		return values, nil
+	}
+
+	// empty value clears the field
+	if value == "" && schema.Type != "string" {
+		return nil, nil
+	}
+
+	switch schema.Type {
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+
+		return number, nil
+
+	case "option", "option-with-child":
+		return map[string]string{"value": value}, nil
+
+	case "priority", "resolution", "version", "component", "user",
+		"issuetype", "securitylevel", "group":
+		return map[string]string{"name": value}, nil
+
+	case "issuelink", "issuelinks":
+		return map[string]string{"key": value}, nil
+
+	default:
+		return value, nil
+	}
+}
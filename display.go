@@ -3,14 +3,15 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"text/template"
+	"time"
 
 	"github.com/reconquest/karma-go"
-	"github.com/reconquest/loreley"
 	"github.com/seletskiy/tplutil"
-	"github.com/tears-of-noobs/gojira"
 )
 
 var DefaultTemplate = template.Must(
@@ -25,12 +26,22 @@ var OnlySummaryTemplate = template.Must(
 	),
 )
 
+var BacklogTemplate = template.Must(
+	template.New("backlog").Parse(
+		"{{.mark}}{{.key}}\t{{.estimate}}\t{{.stage}}\t{{.name}}\t{{.summary}}",
+	),
+)
+
+// displayIssues displays issues using stage templates, defaultTemplate is
+// used for stages without template, DefaultTemplate is used if it is nil.
 func displayIssues(
-	issues []gojira.Issue,
+	issues []Issue,
 	activeIssueKey string,
 	showName bool,
 	onlySummary bool,
 	workflow Workflow,
+	highlight *regexp.Regexp,
+	defaultTemplate *template.Template,
 ) error {
 	var err error
 
@@ -40,30 +51,13 @@ func displayIssues(
 	templates := map[string]*template.Template{}
 
 	for _, issue := range issues {
-		issueMark := ""
-		isActive := false
-		if issue.Key == activeIssueKey {
-			issueMark = "* "
-			isActive = true
-		}
-
-		name := issue.Fields.Assignee.Name
-		if !showName {
-			name = issue.Fields.Assignee.DisplayName
-		}
+		view := getIssueListView(issue, activeIssueKey, showName)
 
-		view := map[string]interface{}{
-			"is_active":             isActive,
-			"mark":                  issueMark,
-			"key":                   issue.Key,
-			"stage":                 issue.Fields.Status.Name,
-			"name":                  name,
-			"assignee_name":         issue.Fields.Assignee.Name,
-			"assignee_display_name": issue.Fields.Assignee.DisplayName,
-			"summary":               issue.Fields.Summary,
+		tpl := defaultTemplate
+		if tpl == nil {
+			tpl = DefaultTemplate
 		}
 
-		tpl := DefaultTemplate
 		if onlySummary {
 			tpl = OnlySummaryTemplate
 		} else {
@@ -102,14 +96,12 @@ func displayIssues(
 
 	board.Flush()
 
-	loreley.DelimLeft = "<"
-	loreley.DelimRight = ">"
+	contents := escapeLoreley(buffer.String())
+	if highlight != nil {
+		contents = highlight.ReplaceAllString(contents, highlightStyle)
+	}
 
-	result, err := loreley.CompileAndExecuteToString(
-		strings.NewReplacer("<", `<"<">`).Replace(buffer.String()),
-		nil,
-		nil,
-	)
+	result, err := colorize(contents)
 	if err != nil {
 		return karma.Format(
 			err,
@@ -122,37 +114,135 @@ func displayIssues(
 	return nil
 }
 
-func displayIssue(issue *gojira.Issue) error {
-	fmt.Printf("Issue:    %s\n", issue.Key)
-	fmt.Printf("Assignee: %s\n", issue.Fields.Assignee.DisplayName)
-	fmt.Printf("Status:   %s\n", issue.Fields.Status.Name)
-	fmt.Printf("Summary:  %s\n\n", issue.Fields.Summary)
+const highlightStyle = `<bold><fg 1>${0}<reset>`
+
+func escapeLoreley(text string) string {
+	return strings.NewReplacer("<", `<"<">`).Replace(text)
+}
+
+// getHighlightPattern returns pattern which matches any of specified terms
+// in loreley-escaped text.
+func getHighlightPattern(terms []string) *regexp.Regexp {
+	alternatives := []string{}
+	for _, term := range terms {
+		for _, word := range strings.Fields(term) {
+			word = strings.Trim(word, `"'`)
+			// such words can't be highlighted without breaking escaping
+			if word == "" || strings.ContainsAny(word, `">`) {
+				continue
+			}
 
-	desc := "<no description>"
-	if issue.Fields.Description != nil {
-		desc = issue.Fields.Description.(string)
+			alternatives = append(
+				alternatives,
+				regexp.QuoteMeta(escapeLoreley(word)),
+			)
+		}
 	}
 
-	fmt.Printf("%s\n", desc)
+	if len(alternatives) == 0 {
+		return nil
+	}
 
-	return nil
+	return regexp.MustCompile("(?i)" + strings.Join(alternatives, "|"))
 }
 
-func displayTransitions(transitions *gojira.Transitions) error {
-	for _, transition := range transitions.Transitions {
-		fmt.Printf("%3s %s \n", transition.Id, transition.To.Name)
+// getIssueListView returns template data for issue in list or on kanban
+// board.
+func getIssueListView(
+	issue Issue,
+	activeIssueKey string,
+	showName bool,
+) map[string]interface{} {
+	issueMark := ""
+	isActive := false
+	if issue.Key == activeIssueKey {
+		issueMark = "* "
+		isActive = true
+	}
+
+	name := issue.Fields.Assignee.Name
+	if !showName {
+		name = issue.Fields.Assignee.DisplayName
+	}
+
+	return map[string]interface{}{
+		"is_active":             isActive,
+		"mark":                  issueMark,
+		"key":                   issue.Key,
+		"stage":                 issue.Fields.Status.Name,
+		"name":                  name,
+		"assignee_name":         issue.Fields.Assignee.Name,
+		"assignee_display_name": issue.Fields.Assignee.DisplayName,
+		"summary":               issue.Fields.Summary,
+		"type":                  issue.Fields.IssueType.Name,
+		"priority":              issue.Extra.Priority.Name,
+		"labels":                issue.Fields.Labels,
+		"age":                   formatAge(issue.Fields.Created),
+		"estimate":              formatEstimate(issue.Estimate),
+	}
+}
+
+func formatEstimate(estimate *float64) string {
+	if estimate == nil {
+		return "-"
+	}
+
+	return strconv.FormatFloat(*estimate, 'g', -1, 64)
+}
+
+// formatAge returns time passed since specified Jira time in the largest
+// suitable unit, like 3d or 5h.
+func formatAge(value string) string {
+	moment, err := time.Parse(jiraTimeLayout, value)
+	if err != nil {
+		return ""
+	}
+
+	age := time.Since(moment)
+
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	}
+}
+
+// getMarkupStyles returns field value rendered from Jira markup into loreley
+// styled text, or escaped as is if raw is true.
+func getMarkupStyles(source interface{}, raw bool) string {
+	if raw {
+		return escapeLoreley(formatMarkup(source))
+	}
+
+	return renderMarkup(source, getTerminalWidth())
+}
+
+func displayTransitions(transitions []transition) error {
+	if len(transitions) > 0 {
+		fmt.Println(formatTransitions(transitions))
 	}
 
 	return nil
 }
 
-func displayComments(comments *gojira.Comments) error {
-	for _, comment := range comments.Comments {
+func displayComments(comments []IssueComment, raw bool) error {
+	for _, comment := range comments {
+		body, err := colorize(getMarkupStyles(comment.Body, raw))
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to colorize comment: %s", comment.ID,
+			)
+		}
+
 		fmt.Printf("\n################\n")
-		fmt.Printf("ID:     %s\n", comment.Id)
+		fmt.Printf("ID:     %s\n", comment.ID)
 		fmt.Printf("Author: %s\n", comment.Author.DisplayName)
 		fmt.Printf("Update: %s\n", comment.Updated)
-		fmt.Printf("Comment: \n%s\n", comment.Body)
+		fmt.Printf("Comment: \n%s\n", body)
 	}
 
 	return nil
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// draftErrorPrefix marks comment lines with errors of the last attempt to
+// create issue, such lines are not saved in draft.
+const draftErrorPrefix = commentPrefix + "! "
+
+// draft is an editor buffer of issue which is failed to be created, it is
+// stored as two files: buffer itself and its metadata.
+type draft struct {
+	Project   string   `json:"project"`
+	IssueType string   `json:"issue_type"`
+	ParentKey string   `json:"parent_key"`
+	Errors    []string `json:"errors"`
+
+	path string
+}
+
+func getDraftsDirectory() (string, error) {
+	directory := filepath.Join(os.Getenv("HOME"), ".batrak", "drafts")
+
+	err := os.MkdirAll(directory, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	return directory, nil
+}
+
+// saveDraft writes buffer contents and metadata of draft, new draft file is
+// created if draft has no path yet.
+func saveDraft(draft *draft, contents string) error {
+	if draft.path == "" {
+		directory, err := getDraftsDirectory()
+		if err != nil {
+			return err
+		}
+
+		draft.path = filepath.Join(
+			directory,
+			strconv.FormatInt(time.Now().UnixNano(), 10)+".batrak",
+		)
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(contents, "\n") {
+		if !strings.HasPrefix(line, draftErrorPrefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	err := ioutil.WriteFile(
+		draft.path, []byte(strings.Join(lines, "\n")), 0o600,
+	)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(draft)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(draft.path+".json", metadata, 0o600)
+}
+
+func removeDraft(draft *draft) error {
+	err := os.Remove(draft.path + ".json")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return os.Remove(draft.path)
+}
+
+// getLastDraft returns the most recent draft and its buffer contents.
+func getLastDraft() (*draft, string, error) {
+	directory, err := getDraftsDirectory()
+	if err != nil {
+		return nil, "", err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(directory, "*.batrak"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(paths) == 0 {
+		return nil, "", errors.New("there are no drafts")
+	}
+
+	// draft names are creation timestamps
+	sort.Strings(paths)
+
+	path := paths[len(paths)-1]
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	metadata, err := ioutil.ReadFile(path + ".json")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result draft
+	err = json.Unmarshal(metadata, &result)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result.path = path
+
+	return &result, string(contents), nil
+}
+
+// getErrorLines returns messages of error returned by Jira, field errors are
+// returned one per line.
+func getErrorLines(err error) []string {
+	var failure apiError
+	if errors.As(err, &failure) && len(failure.Messages()) > 0 {
+		return failure.Messages()
+	}
+
+	return strings.Split(err.Error(), "\n")
+}
+
+func handleLastDraftMode(options createOptions) error {
+	draft, contents, err := getLastDraft()
+	if err != nil {
+		return err
+	}
+
+	preface := fmt.Sprintf(
+		"%sIssue %s in project %s was not created:\n",
+		draftErrorPrefix, draft.IssueType, draft.Project,
+	)
+	for _, line := range draft.Errors {
+		preface += draftErrorPrefix + "  " + line + "\n"
+	}
+
+	return createIssueInEditor(draft, preface+contents, options)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/reconquest/executil-go"
+	"github.com/reconquest/karma-go"
+)
+
+const prefaceEditIssue = `
+
+#! Edit summary, description and fields of %s.
+#! The first line of text is the summary and the rest is description.
+#! Only changed fields are updated, empty value clears the field.
+`
+
+type editMeta struct {
+	Fields map[string]metaField `json:"fields"`
+}
+
+// editableItemTypes are types of array items which can be edited as
+// comma-separated list.
+var editableItemTypes = map[string]bool{
+	"string":    true,
+	"option":    true,
+	"version":   true,
+	"component": true,
+	"user":      true,
+	"group":     true,
+}
+
+func getEditMetaFields(issueKey string) (map[string]metaField, error) {
+	var meta editMeta
+	err := requestAPI("GET", "/issue/"+issueKey+"/editmeta", nil, &meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return meta.Fields, nil
+}
+
+// handleEditMode opens issue summary, description and editable fields in
+// editor and updates fields which are changed.
+func handleEditMode(issue *Issue) error {
+	metaFields, err := getEditMetaFields(issue.Key)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get editable fields of issue: %s", issue.Key,
+		)
+	}
+
+	original := getEditBuffer(issue, metaFields)
+
+	contents, err := editTemporaryFile(
+		original+fmt.Sprintf(prefaceEditIssue, issue.Key),
+		issue.Key+".batrak",
+	)
+	if err != nil {
+		if executil.IsExitError(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	summary, desc, fields, ok := parseIssueBuffer(contents)
+	if !ok {
+		log.Println("Aborted")
+		return nil
+	}
+
+	originalSummary, originalDesc, originalFields, _ := parseIssueBuffer(original)
+
+	changes := map[string]interface{}{}
+	if summary != originalSummary {
+		changes["summary"] = summary
+	}
+
+	if desc != originalDesc {
+		changes["description"] = desc
+	}
+
+	changedFields := map[string]string{}
+	for key, value := range fields {
+		if strings.TrimSpace(value) != strings.TrimSpace(originalFields[key]) {
+			changedFields[key] = value
+		}
+	}
+
+	coerced, err := coerceFields(changedFields, metaFields)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range coerced {
+		changes[key] = value
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("Issue %s is not changed\n", issue.Key)
+		return nil
+	}
+
+	err = requestAPI(
+		"PUT", "/issue/"+issue.Key,
+		map[string]interface{}{"fields": changes},
+		nil,
+	)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to update issue: %s", issue.Key,
+		)
+	}
+
+	names := []string{}
+	for key := range changes {
+		if field, ok := metaFields[key]; ok {
+			key = field.Name
+		}
+
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	fmt.Printf("Issue %s updated: %s\n", issue.Key, strings.Join(names, ", "))
+
+	return nil
+}
+
+// getEditBuffer returns issue summary, description and editable fields in
+// format of issue creation buffer.
+func getEditBuffer(issue *Issue, metaFields map[string]metaField) string {
+	ids := []string{}
+	names := map[string]int{}
+	for id, field := range metaFields {
+		ids = append(ids, id)
+		names[strings.ToLower(field.Name)]++
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if metaFields[ids[i]].Name == metaFields[ids[j]].Name {
+			return ids[i] < ids[j]
+		}
+
+		return metaFields[ids[i]].Name < metaFields[ids[j]].Name
+	})
+
+	lines := []string{}
+	for _, id := range ids {
+		if id == "summary" || id == "description" {
+			continue
+		}
+
+		field := metaFields[id]
+
+		value, ok := formatFieldValue(field.Schema, issue.RawFields[id])
+		if !ok {
+			continue
+		}
+
+		// field name can't be used if it's ambiguous in $name: value line
+		// or if several fields have the same name
+		name := field.Name
+		if strings.Contains(name, ":") || strings.Contains(name, ".") ||
+			names[strings.ToLower(name)] > 1 {
+			name = id
+		}
+
+		lines = append(lines, "$"+name+": "+value)
+	}
+
+	return issue.Fields.Summary + "\n\n" +
+		formatMarkup(issue.Fields.Description) + "\n\n" +
+		strings.Join(lines, "\n")
+}
+
+// formatFieldValue formats field value the same way as it's written in
+// $field: line, it returns false if field of such type can't be edited.
+func formatFieldValue(schema fieldSchema, raw json.RawMessage) (string, bool) {
+	if schema.Type == "array" {
+		if !editableItemTypes[schema.Items] {
+			return "", false
+		}
+
+		var items []json.RawMessage
+		if len(raw) > 0 {
+			err := json.Unmarshal(raw, &items)
+			if err != nil {
+				return "", false
+			}
+		}
+
+		values := []string{}
+		for _, item := range items {
+			value, ok := formatFieldValue(fieldSchema{Type: schema.Items}, item)
+			if !ok {
+				return "", false
+			}
+
+			values = append(values, value)
+		}
+
+		return strings.Join(values, ", "), true
+	}
+
+	var value interface{}
+	if len(raw) > 0 {
+		err := json.Unmarshal(raw, &value)
+		if err != nil {
+			return "", false
+		}
+	}
+
+	switch schema.Type {
+	case "string", "date", "datetime":
+		if value == nil {
+			return "", true
+		}
+
+		text, ok := value.(string)
+
+		return text, ok && !strings.Contains(text, "\n")
+
+	case "number":
+		if value == nil {
+			return "", true
+		}
+
+		number, ok := value.(float64)
+
+		return strconv.FormatFloat(number, 'g', -1, 64), ok
+
+	case "option", "option-with-child", "priority", "resolution", "version",
+		"component", "user", "issuetype", "securitylevel", "group":
+		if value == nil {
+			return "", true
+		}
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		for _, key := range []string{"value", "name"} {
+			if text, ok := object[key].(string); ok {
+				return text, true
+			}
+		}
+
+		return "", false
+
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGetEditBuffer(t *testing.T) {
+	issue := &Issue{
+		RawFields: map[string]json.RawMessage{
+			"labels":            json.RawMessage(`["a","b"]`),
+			"customfield_10001": json.RawMessage(`"backend"`),
+			"customfield_10002": json.RawMessage(`"mobile"`),
+			"customfield_10003": json.RawMessage(`3`),
+			"customfield_10004": json.RawMessage(`"x"`),
+		},
+	}
+	issue.Fields.Summary = "Title"
+
+	metaFields := map[string]metaField{
+		"summary":           {Name: "Summary", Schema: fieldSchema{Type: "string"}},
+		"labels":            {Name: "Labels", Schema: fieldSchema{Type: "array", Items: "string"}},
+		"customfield_10001": {Name: "Team", Schema: fieldSchema{Type: "string"}},
+		"customfield_10002": {Name: "team", Schema: fieldSchema{Type: "string"}},
+		"customfield_10003": {Name: "Story Points", Schema: fieldSchema{Type: "number"}},
+		"customfield_10004": {Name: "Start: date", Schema: fieldSchema{Type: "string"}},
+	}
+
+	expected := []string{
+		"$Labels: a, b",
+		"$customfield_10004: x",
+		"$Story Points: 3",
+		"$customfield_10001: backend",
+		"$customfield_10002: mobile",
+	}
+
+	buffer := getEditBuffer(issue, metaFields)
+
+	lines := strings.Split(buffer, "\n")
+	if len(lines) < len(expected) {
+		t.Fatalf("unexpected buffer: %q", buffer)
+	}
+
+	fields := lines[len(lines)-len(expected):]
+	if strings.Join(fields, "\n") != strings.Join(expected, "\n") {
+		t.Errorf(
+			"expected fields:\n%s\ngot:\n%s",
+			strings.Join(expected, "\n"), strings.Join(fields, "\n"),
+		)
+	}
+
+	_, _, parsed, _ := parseIssueBuffer(buffer)
+	for _, id := range []string{"customfield_10001", "customfield_10002"} {
+		found, _, ok := findMetaField(id, metaFields)
+		if !ok || found != id {
+			t.Errorf("%s: field is not resolved by id", id)
+		}
+
+		if _, ok := parsed[id]; !ok {
+			t.Errorf("%s: field is not written by id", id)
+		}
+	}
+}
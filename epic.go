@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/reconquest/karma-go"
+)
+
+type epicProgress struct {
+	total           int
+	done            int
+	points          float64
+	completedPoints float64
+}
+
+// getEpicChildrenJQL returns JQL which matches children of specified epics
+// linked using legacy Epic Link field or using parent field in newer Jira
+// issue hierarchy.
+func getEpicChildrenJQL(epicKeys ...string) (string, error) {
+	keys := strings.Join(epicKeys, ", ")
+
+	chunks := []string{"parent in (" + keys + ")"}
+
+	epicField, ok, err := findFieldByType(fieldTypeEpicLink)
+	if err != nil {
+		return "", karma.Format(
+			err,
+			"unable to find epic link field",
+		)
+	}
+
+	if ok {
+		chunks = append(chunks, getFieldClause(epicField)+" in ("+keys+")")
+	}
+
+	return "(" + strings.Join(chunks, " OR ") + ")", nil
+}
+
+// getFieldClause returns name of field which can be used in JQL.
+func getFieldClause(field field) string {
+	if id := strings.TrimPrefix(field.ID, "customfield_"); id != field.ID {
+		return "cf[" + id + "]"
+	}
+
+	return field.ID
+}
+
+// moveIssuesToEpic adds issues to epic or removes them from their epics if
+// epic key is empty. Jira Software updates Epic Link or parent field
+// depending on the project hierarchy.
+func moveIssuesToEpic(epicKey string, keys []string) error {
+	if epicKey == "" {
+		epicKey = "none"
+	}
+
+	return requestAgile(
+		"POST", "/epic/"+epicKey+"/issue",
+		map[string]interface{}{"issues": keys},
+		nil,
+	)
+}
+
+func handleEpicListMode(
+	config *Configuration,
+	query string,
+	limit int,
+) error {
+	epicsJQL, err := getEpicsJQL()
+	if err != nil {
+		return err
+	}
+
+	jql := "project = " + config.ProjectName + " AND " + epicsJQL
+	if query != "" {
+		jql += " AND (" + query + ")"
+	}
+
+	epics, err := getIssues(jql+" ORDER BY created DESC", limit)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to search epics of project: %s", config.ProjectName,
+		)
+	}
+
+	if len(epics.Issues) == 0 {
+		return nil
+	}
+
+	progress, pointsField, err := getEpicsProgress(epics.Issues)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	for _, epic := range epics.Issues {
+		epicProgress := progress[epic.Key]
+
+		points := ""
+		if pointsField != "" {
+			points = fmt.Sprintf(
+				"%g/%g points",
+				epicProgress.completedPoints, epicProgress.points,
+			)
+		}
+
+		fmt.Fprintf(
+			writer, "%s\t%s\t%d/%d issues\t%s\t%s\n",
+			epic.Key,
+			epic.Fields.Status.Name,
+			epicProgress.done, epicProgress.total,
+			points,
+			epic.Fields.Summary,
+		)
+	}
+
+	return writer.Flush()
+}
+
+// getEpicsProgress returns amount of done and total issues and story points
+// of children of specified epics and identifier of story points field.
+func getEpicsProgress(
+	epics []Issue,
+) (map[string]*epicProgress, string, error) {
+	keys := []string{}
+	progress := map[string]*epicProgress{}
+	for _, epic := range epics {
+		keys = append(keys, epic.Key)
+		progress[epic.Key] = &epicProgress{}
+	}
+
+	jql, err := getEpicChildrenJQL(keys...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fields := []string{"parent"}
+
+	epicField, ok, err := findFieldByType(fieldTypeEpicLink)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if ok {
+		fields = append(fields, epicField.ID)
+	}
+
+	pointsField, ok, err := findStoryPointsField()
+	if err != nil {
+		return nil, "", karma.Format(
+			err,
+			"unable to find story points field",
+		)
+	}
+
+	if ok {
+		fields = append(fields, pointsField.ID)
+	}
+
+	children, err := getAllIssues(jql, fields...)
+	if err != nil {
+		return nil, "", karma.Format(
+			err,
+			"unable to search children of epics",
+		)
+	}
+
+	for _, child := range children {
+		epicKey, err := getIssueEpic(&child)
+		if err != nil {
+			return nil, "", err
+		}
+
+		epic, ok := progress[epicKey]
+		if !ok {
+			continue
+		}
+
+		var points float64
+		if pointsField.ID != "" {
+			_, err = child.GetField(pointsField.ID, &points)
+			if err != nil {
+				return nil, "", karma.Format(
+					err,
+					"unable to get story points of issue: %s", child.Key,
+				)
+			}
+		}
+
+		epic.total++
+		epic.points += points
+
+		if child.Extra.Status.Category.Key == statusCategoryDone {
+			epic.done++
+			epic.completedPoints += points
+		}
+	}
+
+	return progress, pointsField.ID, nil
+}
+
+// handleEpicMoveMode adds issues to epic or removes them from their epics if
+// epic key is empty.
+func handleEpicMoveMode(epicKey string, keys []string) error {
+	err := moveIssuesToEpic(epicKey, keys)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to move issues: %s", strings.Join(keys, ", "),
+		)
+	}
+
+	if epicKey == "" {
+		fmt.Printf("Issues %s removed from epic\n", strings.Join(keys, ", "))
+	} else {
+		fmt.Printf(
+			"Issues %s added to epic %s\n",
+			strings.Join(keys, ", "), epicKey,
+		)
+	}
+
+	return nil
+}
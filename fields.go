@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+)
+
+const (
+	fieldTypeSprint   = "com.pyxis.greenhopper.jira:gh-sprint"
+	fieldTypeEpicLink = "com.pyxis.greenhopper.jira:gh-epic-link"
+)
+
+// storyPointsFieldNames are names of story points field in Jira Server and
+// Jira Cloud.
+var storyPointsFieldNames = []string{"Story Points", "Story point estimate"}
+
+type field struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Custom bool        `json:"custom"`
+	Schema fieldSchema `json:"schema"`
+}
+
+type fieldSchema struct {
+	Type   string `json:"type"`
+	Items  string `json:"items"`
+	System string `json:"system"`
+	Custom string `json:"custom"`
+}
+
+var cachedFields []field
+
+func getFields() ([]field, error) {
+	if cachedFields != nil {
+		return cachedFields, nil
+	}
+
+	var fields []field
+	err := requestAPI("GET", "/field", nil, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedFields = fields
+
+	return fields, nil
+}
+
+// findFieldByType returns field with specified custom schema type, like
+// Sprint or Epic Link fields of Jira Software.
+func findFieldByType(customType string) (field, bool, error) {
+	fields, err := getFields()
+	if err != nil {
+		return field{}, false, err
+	}
+
+	for _, field := range fields {
+		if field.Schema.Custom == customType {
+			return field, true, nil
+		}
+	}
+
+	return field{}, false, nil
+}
+
+// findStoryPointsField returns story points field of Jira Software.
+func findStoryPointsField() (field, bool, error) {
+	for _, name := range storyPointsFieldNames {
+		field, ok, err := findField(name)
+		if err != nil || ok {
+			return field, ok, err
+		}
+	}
+
+	return field{}, false, nil
+}
+
+// findField returns field which identifier or name equals to specified one
+// case-insensitively.
+func findField(name string) (field, bool, error) {
+	fields, err := getFields()
+	if err != nil {
+		return field{}, false, err
+	}
+
+	for _, field := range fields {
+		if field.ID == name || strings.EqualFold(field.Name, name) {
+			return field, true, nil
+		}
+	}
+
+	return field{}, false, nil
+}
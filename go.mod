@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/BurntSushi/toml v1.0.0
 	github.com/docopt/docopt-go v0.0.0-20180111231733-ee0de3bc6815
+	github.com/mattn/go-runewidth v0.0.9
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/reconquest/executil-go v0.0.0-20181110204642-1f5c2d67813f
 	github.com/reconquest/karma-go v0.0.0-20211029072727-6027c6225ce4
@@ -12,12 +13,11 @@ require (
 	github.com/seletskiy/tplutil v0.0.0-20200921103632-f880f6245597
 	github.com/tears-of-noobs/gojira v0.0.0-20160602095719-20d1dcce5c33
 	github.com/zazab/zhash v0.0.0-20210630080733-6e809466f8d3
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 )
 
 require (
-	github.com/mattn/go-runewidth v0.0.9 // indirect
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
 	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
-	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
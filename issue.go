@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/tears-of-noobs/gojira"
+)
+
+// Issue is a gojira.Issue which also carries fields which are not known to
+// gojira.
+type Issue struct {
+	gojira.Issue
+	Extra IssueExtraFields `json:"-"`
+
+	// RawFields contains all issue fields as is, it is used for reading
+	// custom fields.
+	RawFields map[string]json.RawMessage `json:"-"`
+
+	// Estimate is issue estimate in units of board estimation, it is known
+	// only for issues of Jira Software board backlog.
+	Estimate *float64 `json:"-"`
+}
+
+type IssueExtraFields struct {
+	Priority     IssueNamedField          `json:"priority"`
+	Resolution   IssueNamedField          `json:"resolution"`
+	Reporter     gojira.IssueFieldCreator `json:"reporter"`
+	Components   []IssueNamedField        `json:"components"`
+	FixVersions  []IssueNamedField        `json:"fixVersions"`
+	Parent       *Issue                   `json:"parent"`
+	Subtasks     []Issue                  `json:"subtasks"`
+	Attachments  []IssueAttachment        `json:"attachment"`
+	TimeTracking IssueTimeTracking        `json:"timetracking"`
+	Comment      IssueComments            `json:"comment"`
+	Status       IssueStatus              `json:"status"`
+}
+
+// IssueStatus is an issue status with its category, category key is one of
+// new, indeterminate or done.
+type IssueStatus struct {
+	Category struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+const statusCategoryDone = "done"
+
+type IssueNamedField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type IssueAttachment struct {
+	Filename string                   `json:"filename"`
+	Size     int64                    `json:"size"`
+	Author   gojira.IssueFieldCreator `json:"author"`
+	Created  string                   `json:"created"`
+	Content  string                   `json:"content"`
+}
+
+type IssueTimeTracking struct {
+	OriginalEstimate  string `json:"originalEstimate"`
+	RemainingEstimate string `json:"remainingEstimate"`
+	TimeSpent         string `json:"timeSpent"`
+}
+
+// IssueComments are comments embedded into issue fields, unlike
+// gojira.Comment body can be Atlassian Document Format document.
+type IssueComments struct {
+	Total    int            `json:"total"`
+	Comments []IssueComment `json:"comments"`
+}
+
+type IssueComment struct {
+	ID      string                   `json:"id"`
+	Author  gojira.IssueFieldCreator `json:"author"`
+	Body    interface{}              `json:"body"`
+	Created string                   `json:"created"`
+	Updated string                   `json:"updated"`
+}
+
+// getIssueComments returns all comments of issue, comments are requested
+// page by page.
+func getIssueComments(issueKey string) ([]IssueComment, error) {
+	comments := []IssueComment{}
+
+	for {
+		var reply IssueComments
+		err := requestAPI(
+			"GET",
+			"/issue/"+issueKey+"/comment?startAt="+strconv.Itoa(len(comments)),
+			nil, &reply,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, reply.Comments...)
+
+		if len(reply.Comments) == 0 || len(comments) >= reply.Total {
+			return comments, nil
+		}
+	}
+}
+
+func (issue *Issue) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, &issue.Issue)
+	if err != nil {
+		return err
+	}
+
+	var raw struct {
+		Fields json.RawMessage `json:"fields"`
+	}
+
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	if len(raw.Fields) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(raw.Fields, &issue.RawFields)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw.Fields, &issue.Extra)
+}
+
+// GetField decodes issue field with specified identifier into result and
+// returns false if issue has no such field or it is empty.
+func (issue *Issue) GetField(id string, result interface{}) (bool, error) {
+	value, ok := issue.RawFields[id]
+	if !ok || string(value) == "null" {
+		return false, nil
+	}
+
+	err := json.Unmarshal(value, result)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func getIssue(issueKey string) (*Issue, error) {
+	var issue Issue
+	err := requestAPI("GET", "/issue/"+issueKey, nil, &issue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issue, nil
+}
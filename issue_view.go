@@ -0,0 +1,401 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/reconquest/karma-go"
+	"github.com/seletskiy/tplutil"
+)
+
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+var issueTemplateFunctions = template.FuncMap{
+	"join": strings.Join,
+}
+
+var DefaultIssueTemplate = template.Must(
+	template.New("issue").Funcs(issueTemplateFunctions).Parse(
+		`<bold>{{.key}}<reset> {{.summary}}
+
+Type:        {{.type}}
+Status:      {{.status}}{{with .resolution}} ({{.}}){{end}}
+Priority:    {{.priority}}
+Assignee:    {{.assignee}}
+Reporter:    {{.reporter}}
+Created:     {{.created}}
+Updated:     {{.updated}}
+{{- with .labels}}
+Labels:      {{join . ", "}}
+{{- end}}
+{{- with .components}}
+Components:  {{join . ", "}}
+{{- end}}
+{{- with .fix_versions}}
+Fix:         {{join . ", "}}
+{{- end}}
+{{- with .sprints}}
+Sprint:      {{join . ", "}}
+{{- end}}
+{{- with .epic}}
+Epic:        {{.}}
+{{- end}}
+{{- with .parent}}
+Parent:      {{.key}} [{{.status}}] {{.summary}}
+{{- end}}
+{{- if .subtasks_total}}
+Subtasks:    {{.subtasks_done}} of {{.subtasks_total}} done
+{{- end}}
+{{- if or .time_original .time_remaining .time_spent}}
+Time:        original {{or .time_original "-"}}, remaining {{or .time_remaining "-"}}, spent {{or .time_spent "-"}}
+{{- end}}
+{{- with .subtasks}}
+
+<bold>Subtasks<reset>
+{{- range .}}
+  {{.key}} [{{.status}}] {{.summary}}{{with .assignee}} ({{.}}){{end}}
+{{- end}}
+{{- end}}
+{{- with .links}}
+
+<bold>Links<reset>
+{{- range .}}
+  {{.relation}} {{.key}} [{{.status}}] {{.summary}}
+{{- end}}
+{{- end}}
+{{- with .attachments}}
+
+<bold>Attachments<reset>
+{{- range .}}
+  {{.filename}} ({{.size}}, {{.author}}, {{.created}})
+{{- end}}
+{{- end}}
+
+{{or .description "<\"<\">no description>"}}
+{{- if .comments}}
+
+<bold>Comments<reset> ({{len .comments}} of {{.comments_total}})
+{{- range .comments}}
+
+<bold>{{.author}}<reset>, {{.created}} #{{.id}}
+{{.body}}
+{{- end}}
+{{- end}}
+`,
+	),
+)
+
+var reSprintName = regexp.MustCompile(`\bname=([^,\]]*)`)
+
+func displayIssue(
+	issue *Issue,
+	issueTemplate string,
+	lastComments int,
+	raw bool,
+	withSubtasks bool,
+) error {
+	tpl := DefaultIssueTemplate
+	if issueTemplate != "" {
+		var err error
+		tpl, err = template.New("issue").
+			Funcs(issueTemplateFunctions).
+			Parse(issueTemplate)
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to parse issue template",
+			)
+		}
+	}
+
+	view, err := getIssueView(issue, lastComments, raw, withSubtasks)
+	if err != nil {
+		return err
+	}
+
+	contents, err := tplutil.ExecuteToString(tpl, view)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to execute template: %s", tpl.Name(),
+		)
+	}
+
+	result, err := colorize(contents)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to colorize output",
+		)
+	}
+
+	fmt.Print(result)
+
+	return nil
+}
+
+// getIssueView returns template data for issue view, all values are escaped
+// for loreley and markup fields are rendered unless raw is true. Subtasks
+// are listed only if withSubtasks is true, otherwise only their amount is
+// known.
+func getIssueView(
+	issue *Issue,
+	lastComments int,
+	raw bool,
+	withSubtasks bool,
+) (map[string]interface{}, error) {
+	sprints, err := getIssueSprints(issue)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to get issue sprints",
+		)
+	}
+
+	epic, err := getIssueEpic(issue)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to get issue epic",
+		)
+	}
+
+	assignee := issue.Fields.Assignee.DisplayName
+	if assignee == "" {
+		assignee = "Unassigned"
+	}
+
+	view := map[string]interface{}{
+		"key":            issue.Key,
+		"summary":        escapeLoreley(issue.Fields.Summary),
+		"type":           escapeLoreley(issue.Fields.IssueType.Name),
+		"status":         escapeLoreley(issue.Fields.Status.Name),
+		"priority":       escapeLoreley(issue.Extra.Priority.Name),
+		"resolution":     escapeLoreley(issue.Extra.Resolution.Name),
+		"assignee":       escapeLoreley(assignee),
+		"assignee_name":  escapeLoreley(issue.Fields.Assignee.Name),
+		"reporter":       escapeLoreley(issue.Extra.Reporter.DisplayName),
+		"reporter_name":  escapeLoreley(issue.Extra.Reporter.Name),
+		"created":        formatTime(issue.Fields.Created),
+		"updated":        formatTime(issue.Fields.Updated),
+		"labels":         escapeLoreleyAll(issue.Fields.Labels),
+		"components":     escapeLoreleyAll(getNames(issue.Extra.Components)),
+		"fix_versions":   escapeLoreleyAll(getNames(issue.Extra.FixVersions)),
+		"sprints":        escapeLoreleyAll(sprints),
+		"epic":           escapeLoreley(epic),
+		"time_original":  issue.Extra.TimeTracking.OriginalEstimate,
+		"time_remaining": issue.Extra.TimeTracking.RemainingEstimate,
+		"time_spent":     issue.Extra.TimeTracking.TimeSpent,
+		"description":    getMarkupStyles(issue.Fields.Description, raw),
+		"comments_total": issue.Extra.Comment.Total,
+	}
+
+	if parent := issue.Extra.Parent; parent != nil {
+		view["parent"] = getIssueLinkView(parent, "")
+	}
+
+	subtasksDone := 0
+	for _, subtask := range issue.Extra.Subtasks {
+		if subtask.Extra.Status.Category.Key == statusCategoryDone {
+			subtasksDone++
+		}
+	}
+
+	view["subtasks_total"] = len(issue.Extra.Subtasks)
+	view["subtasks_done"] = subtasksDone
+
+	subtasks := []map[string]interface{}{}
+	if withSubtasks {
+		// subtasks embedded into issue have no assignee
+		issues, err := getSubtasks(issue)
+		if err != nil {
+			return nil, karma.Format(
+				err,
+				"unable to get subtasks",
+			)
+		}
+
+		for i := range issues {
+			subtasks = append(subtasks, getIssueLinkView(&issues[i], ""))
+		}
+	}
+
+	view["subtasks"] = subtasks
+
+	links := []map[string]interface{}{}
+	for _, link := range issue.Fields.IssueLinks {
+		linked := &Issue{Issue: link.OutwardIssue}
+		relation := link.Type["outward"]
+		if link.OutwardIssue.Key == "" {
+			linked = &Issue{Issue: link.InwardIssue}
+			relation = link.Type["inward"]
+		}
+
+		links = append(links, getIssueLinkView(linked, relation))
+	}
+
+	view["links"] = links
+
+	attachments := []map[string]interface{}{}
+	for _, attachment := range issue.Extra.Attachments {
+		attachments = append(attachments, map[string]interface{}{
+			"filename": escapeLoreley(attachment.Filename),
+			"size":     formatSize(attachment.Size),
+			"author":   escapeLoreley(attachment.Author.DisplayName),
+			"created":  formatTime(attachment.Created),
+			"url":      attachment.Content,
+		})
+	}
+
+	view["attachments"] = attachments
+
+	comments := issue.Extra.Comment.Comments
+	if lastComments >= 0 && len(comments) > lastComments {
+		comments = comments[len(comments)-lastComments:]
+	}
+
+	commentViews := []map[string]interface{}{}
+	for _, comment := range comments {
+		commentViews = append(commentViews, map[string]interface{}{
+			"id":      comment.ID,
+			"author":  escapeLoreley(comment.Author.DisplayName),
+			"created": formatTime(comment.Created),
+			"updated": formatTime(comment.Updated),
+			"body":    getMarkupStyles(comment.Body, raw),
+		})
+	}
+
+	view["comments"] = commentViews
+
+	return view, nil
+}
+
+func getIssueLinkView(issue *Issue, relation string) map[string]interface{} {
+	return map[string]interface{}{
+		"relation": escapeLoreley(relation),
+		"key":      issue.Key,
+		"summary":  escapeLoreley(issue.Fields.Summary),
+		"status":   escapeLoreley(issue.Fields.Status.Name),
+		"type":     escapeLoreley(issue.Fields.IssueType.Name),
+		"assignee": escapeLoreley(issue.Fields.Assignee.DisplayName),
+	}
+}
+
+// getIssueSprints returns names of sprints of specified issue. Jira Server
+// returns sprints as serialized java objects while Jira Cloud returns them
+// as JSON objects, so both formats are supported.
+func getIssueSprints(issue *Issue) ([]string, error) {
+	sprintField, ok, err := findFieldByType(fieldTypeSprint)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var values []interface{}
+	_, err = issue.GetField(sprintField.ID, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, value := range values {
+		switch value := value.(type) {
+		case string:
+			if matches := reSprintName.FindStringSubmatch(value); matches != nil {
+				names = append(names, matches[1])
+			}
+
+		case map[string]interface{}:
+			if name, ok := value["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// getIssueEpic returns key of issue epic using Epic Link field or parent
+// issue if it is an epic.
+func getIssueEpic(issue *Issue) (string, error) {
+	epicField, ok, err := findFieldByType(fieldTypeEpicLink)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		var epic string
+		_, err = issue.GetField(epicField.ID, &epic)
+		if err != nil {
+			return "", err
+		}
+
+		if epic != "" {
+			return epic, nil
+		}
+	}
+
+	parent := issue.Extra.Parent
+	if parent != nil {
+		epic, err := isEpic(parent)
+		if err != nil {
+			return "", err
+		}
+
+		if epic {
+			return parent.Key, nil
+		}
+	}
+
+	return "", nil
+}
+
+func getNames(fields []IssueNamedField) []string {
+	names := []string{}
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+func escapeLoreleyAll(values []string) []string {
+	escaped := []string{}
+	for _, value := range values {
+		escaped = append(escaped, escapeLoreley(value))
+	}
+
+	return escaped
+}
+
+func formatTime(value string) string {
+	moment, err := time.Parse(jiraTimeLayout, value)
+	if err != nil {
+		// Jira Software API returns dates in RFC3339
+		moment, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return value
+		}
+	}
+
+	return moment.Local().Format("2006-01-02 15:04")
+}
+
+func formatSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", size, units[unit])
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
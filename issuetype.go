@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	fieldTypeEpicName = "com.pyxis.greenhopper.jira:gh-epic-label"
+
+	// epicHierarchyLevel is hierarchy level of epic issue types in Jira
+	// Cloud, Jira Server doesn't return hierarchy levels.
+	epicHierarchyLevel = 1
+)
+
+type issueType struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Subtask        bool   `json:"subtask"`
+	HierarchyLevel *int   `json:"hierarchyLevel"`
+}
+
+var cachedIssueTypes []issueType
+
+func getIssueTypes() ([]issueType, error) {
+	if cachedIssueTypes != nil {
+		return cachedIssueTypes, nil
+	}
+
+	var types []issueType
+	err := requestAPI("GET", "/issuetype", nil, &types)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedIssueTypes = types
+
+	return types, nil
+}
+
+// getSubtaskType returns name of the first subtask issue type, it is
+// Sub-task in Jira Server and Subtask in Jira Cloud by default.
+func getSubtaskType() (string, error) {
+	types, err := getIssueTypes()
+	if err != nil {
+		return "", err
+	}
+
+	for _, issueType := range types {
+		if issueType.Subtask {
+			return issueType.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("there is no subtask issue type")
+}
+
+// getEpicTypeIDs returns identifiers of epic issue types found by hierarchy
+// level, names of issue types can't be used because they are localized.
+func getEpicTypeIDs() ([]string, error) {
+	types, err := getIssueTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, issueType := range types {
+		if issueType.HierarchyLevel != nil &&
+			*issueType.HierarchyLevel == epicHierarchyLevel {
+			ids = append(ids, issueType.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// getEpicsJQL returns JQL condition which matches epics: epic issue types in
+// Jira Cloud or issues with Epic Name field which only epics have in Jira
+// Server.
+func getEpicsJQL() (string, error) {
+	ids, err := getEpicTypeIDs()
+	if err != nil {
+		return "", err
+	}
+
+	if len(ids) > 0 {
+		return "issuetype in (" + strings.Join(ids, ", ") + ")", nil
+	}
+
+	epicNameField, ok, err := findFieldByType(fieldTypeEpicName)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return getFieldClause(epicNameField) + " is not EMPTY", nil
+	}
+
+	return "", errors.New("unable to find epic issue type")
+}
+
+// isEpic returns true if issue is an epic, its issue type is checked by
+// hierarchy level.
+func isEpic(issue *Issue) (bool, error) {
+	var issueType issueType
+	ok, err := issue.GetField("issuetype", &issueType)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if issueType.HierarchyLevel != nil {
+		return *issueType.HierarchyLevel == epicHierarchyLevel, nil
+	}
+
+	ids, err := getEpicTypeIDs()
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range ids {
+		if id == issueType.ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -1,17 +1,13 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/docopt/docopt-go"
-	"github.com/reconquest/executil-go"
 	"github.com/reconquest/karma-go"
 	"github.com/tears-of-noobs/gojira"
 )
@@ -28,42 +24,178 @@ Usage:
     batrak [options] -A <issue>
     batrak [options] -S <issue>
     batrak [options] -T <issue>
-    batrak [options] -M <issue> [<transition>]
+    batrak [options] -M <issue> [<transition>] [--field <field>]...
     batrak [options] -R <issue> <title>
+    batrak [options] -R <title>
+    batrak [options] -E <issue>
     batrak [options] -C <issue>
     batrak [options] -C -L <issue>
     batrak [options] -C -L <issue> -R <comment>
     batrak [options] -D <issue>
-    batrak [options] -N <project> <issuetype>
+    batrak [options] -N <project> <issuetype> [--field <field>]...
+    batrak [options] -N --last-draft [--field <field>]...
+    batrak [options] search <terms>...
+    batrak [options] sprint [list] [--state <states>]
+    batrak [options] sprint add <sprint> <issues>...
+    batrak [options] sprint remove <issues>...
+    batrak [options] sprint summary [<sprint>]
+    batrak [options] backlog
+    batrak [options] backlog rank <issues>... (--top | --bottom | --before <key> | --after <key>)
+    batrak [options] epic [list]
+    batrak [options] epic show <epic> [-K]
+    batrak [options] epic add <epic> <issues>...
+    batrak [options] epic remove <issues>...
+    batrak [options] subtask add <issue> [<issuetype>] [--field <field>]...
+    batrak [options] subtask list <issue>
+    batrak [options] set <field> [--] <value> <issues>...
+    batrak [options] bulk assign <user>
+    batrak [options] bulk move <transition> [--field <field>]...
+    batrak [options] bulk set <field> [--] <value>
+    batrak [options] bulk label [--] <labels>
+    batrak [options] bulk comment <text>
+    batrak [options] bulk rename <title>
 
 Options:
   -L --list            List issues using specified filter. You can specify <issue>
                         identifier and see issue details.
                         Combine this flag with -K (--kanban) and
                         batrak will list issues in kanban board style.
-    -c <count>         Limit amount of issues. [default: 30]
-    -f <id>            Use specified filter identifier.
+    -c <count>         Limit amount of issues, 30 by default. Issues of board
+                        are not limited by default.
+    -f <id>            Use specified filter identifier. Filter query will be
+                        combined with -q, -m, -o, -c and project.
+    --raw-filter       Use filter as is, ignoring -q, -m, -o, -c and project.
+    --board <id>       List issues of active sprint of specified Jira Software
+                        board or issues of kanban board in board rank order.
+                        Kanban columns are taken from board configuration.
+    --sprint <sprint>  List issues of specified sprint of the board instead of
+                        active one, sprint can be specified by id or name.
     -w --show-name     Show issue assignee username instead of "Display Name".
     -m --my            Show only my issues.
     -q --query <jql>   Specify Jira Query.
     -o --order <jql>   Specify order by fields.
     --only-summary     Show only issue summary.
+    --status <list>    Show only issues with specified comma-separated
+                        statuses. Prefix list with ! to exclude statuses.
+    --assignee <list>  Show only issues of specified assignees, same syntax.
+                        With -N (--new) sets assignee of new issue.
+    --label <list>     Show only issues with specified labels, same syntax.
+                        With -N (--new) sets labels of new issue.
+    --type <list>      Show only issues of specified types, same syntax.
+    --priority <list>  Show only issues with specified priorities, same syntax.
+                        With -N (--new) sets priority of new issue.
+    --text <text>      Show only issues which summary contains specified text
+                        or matches /regexp/. Prefix with ! to exclude.
    -K --kanban         List issues as a Kanban board.
     -s --show-summary  Show summary in Kanban mode.
+    --swimlane <field> Group issues on Kanban board into swimlanes by
+                        assignee, epic, priority, type or label.
+    --layout <layout>  Kanban board layout: table, stacked which lists columns
+                        one after another, or auto which uses stacked layout
+                        if table doesn't fit terminal. [default: auto]
+    --wrap             Wrap issue summaries in Kanban mode instead of
+                        truncating them to fit terminal.
+    --wip-check        Exit with error if Kanban board columns violate WIP
+                        limits of workflow stages.
   -N --new             New issue in the specified <project>.
+    --template <name>  Pre-fill editor with specified create template instead
+                        of template matching project and issue type.
+    --summary <text>   Create issue with specified summary without editor.
+    --description <text>
+                       Description of new issue, use - to read it from stdin.
+    --field <field>    Set field of new issue, value is specified as
+                        key=value, like in $key: value editor lines. Key is
+                        a field identifier or name, value is converted to
+                        field type: comma-separated list for arrays, option
+                        or name for select fields or number.
+    --component <list> Set comma-separated components of new issue.
+    --json             Print created issue as JSON instead of its key.
+    --last-draft       Edit and create the last issue which is failed to be
+                        created. Drafts are saved in ~/.batrak/drafts.
   -A --assign          Assign specified issue.
   -S --start           Start working on specified issue.
   -T --terminate       Stop working on specified issue.
   -M --move            Move specified issue or list available transitions.
+                        Transition can be specified by identifier, name,
+                        target status name or name of workflow stage, case
+                        doesn't matter and beginning of name is enough.
+                        Required fields of transition screen are asked if
+                        they are not specified using --field (key=value)
+                        or the following flags.
+    --resolution <name>
+                       Set resolution of issue during transition.
+    --fix-version <list>
+                       Set comma-separated fix versions during transition.
+    --comment <text>   Add comment to issue during transition.
   -D --delete          Delete specified issue.
+  -E --edit            Edit summary, description and fields of specified
+                        issue in editor, only changed fields are updated.
   -R --rename          Change specified issue title to <title>. If new <title>
                         value starts with s/ then <title> will be used as
-                        expression to sed with old title value as input.
+                        s/pattern/replacement/flags expression to change old
+                        title, flags g and i are supported, delimiter can be
+                        one of / | # ! @ % , : ; ~. If issue is not
+                        specified, expression is applied to all issues
+                        matching -q and -f in the project after
+                        confirmation, like bulk rename does.
+    --dry-run          Show old and new titles without renaming issues.
   -C --comments        Create comment to specified issue.
                         Combine this flag with -L (--list) and
                         batrak will list comments to specified issue.
                         Combine this flag with -D (--delete) and
                         batrak will delete specified comment to specified issue.
+  search               Search issues which summary, description or comments
+                        contain specified <terms>. Matched terms are
+                        highlighted. Use -c, -o, -w, --only-summary to control
+                        output.
+    --all-projects     Search in all projects instead of current one.
+    --in-comments      Search only within comments.
+  sprint               List sprints of the board specified by --board or
+                        board_id setting, add issues to sprint, remove them
+                        to backlog or show sprint summary: amount of
+                        issues and estimates which are currently in sprint
+                        and completed, subtasks are not counted.
+    --state <states>   Show sprints in comma-separated states: active,
+                        future, closed. [default: active,future]
+  backlog              List issues of the board backlog in rank order with
+                        estimates. Use -q, -m, -c, -w, --only-summary to
+                        control output.
+    rank               Rank issues to the top or to the bottom of backlog or
+                        before or after specified issue.
+    --top              Rank issues to the top of backlog.
+    --bottom           Rank issues to the bottom of backlog.
+    --before <key>     Rank issues before specified issue.
+    --after <key>      Rank issues after specified issue.
+  epic                 List epics of the project with amount of done and total
+                        issues and story points, add issues to epic or remove
+                        them from epic. Use -q and -c to filter epics.
+    show               List issues of specified epic, all options of -L
+                        (--list) including -K (--kanban) can be used.
+  subtask              Create subtask of specified issue in editor the same
+                        way as -N (--new) does or list subtasks of issue.
+                        Subtask issue type is detected if not specified.
+  set                  Set field of specified issues to the value, field can be
+                        specified by name or identifier, like priority,
+                        labels, components, fix versions, due date, story
+                        points or any custom field. Values of list fields are
+                        comma-separated, they are added or removed if
+                        prefixed with + or -, like +urgent,-stale. Use --
+                        before value which starts with -.
+  bulk                 Assign, move, set field, add or remove labels, comment or
+                        rename all issues matching -q and -f in the project
+                        or issues which keys are read from stdin. Issues are
+                        listed and changed after confirmation. Labels are
+                        added unless they are prefixed with -.
+    --yes              Don't ask for confirmation.
+    -j --jobs <count>  Amount of issues which are changed at the same time.
+                        [default: 4]
+    --last-comments <count>
+                       Amount of last comments to show with issue.
+                        [default: 3]
+    --with-subtasks    List subtasks with their statuses and assignees in
+                        issue view.
+  --raw                Show issue description and comments as is, without
+                        rendering Jira markup.
   --config <path>      Use specified configuration file.
                         [default: $HOME/.batrakrc]
   -p <project>         Use specified project name instead of config.
@@ -106,7 +238,23 @@ func main() {
 	hooks := NewHooks(config)
 
 	var issueKey string
-	var issue *gojira.Issue
+	var issue *Issue
+	issueKeys := []string{}
+	if keys, ok := args["<issues>"].([]string); ok {
+		for _, key := range keys {
+			issueKeys = append(issueKeys, getIssueKey(key, config.ProjectName))
+		}
+	}
+
+	var boardID int
+	if rawBoardID, ok := args["--board"].(string); ok {
+		boardID, err = strconv.Atoi(rawBoardID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid board id: "+rawBoardID)
+			os.Exit(1)
+		}
+	}
+
 	if args["<issue>"] != nil {
 		issueKey = args["<issue>"].(string)
 
@@ -119,23 +267,13 @@ func main() {
 			config.ProjectName = issueKeyPieces[0]
 		}
 
-		issue, err = gojira.GetIssue(issueKey)
+		issue, err = getIssue(issueKey)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 			os.Exit(1)
 		}
 	}
 
-	if config.ProjectName == "" {
-		fmt.Fprintln(
-			os.Stderr,
-			"project name is empty, "+
-				"you can specify it in config, "+
-				"pass -p flag or just specify an issue",
-		)
-		os.Exit(1)
-	}
-
 	var (
 		listMode      = args["--list"].(bool)
 		moveMode      = args["--move"].(bool)
@@ -145,14 +283,110 @@ func main() {
 		commentsMode  = args["--comments"].(bool)
 		deleteMode    = args["--delete"].(bool)
 		renameMode    = args["--rename"].(bool)
+		editMode      = args["--edit"].(bool)
 		createMode    = args["--new"].(bool)
+		searchMode    = args["search"].(bool)
+		sprintMode    = args["sprint"].(bool)
+		backlogMode   = args["backlog"].(bool)
+		epicMode      = args["epic"].(bool)
+		subtaskMode   = args["subtask"].(bool)
+		setMode       = args["set"].(bool)
+		bulkMode      = args["bulk"].(bool)
 	)
 
+	// issues of epic are listed the same way as other issues
+	epicKey, _ := args["<epic>"].(string)
+	if epicMode && args["show"].(bool) {
+		epicMode = false
+		listMode = true
+	}
+
+	if epicKey != "" {
+		epicKey = getIssueKey(epicKey, config.ProjectName)
+	}
+
+	// issues are listed from board only if it's specified by --board, while
+	// board_id setting is used by commands which work with boards only
+	sprintName, _ := args["--sprint"].(string)
+	if boardID == 0 && (sprintMode || backlogMode || sprintName != "") {
+		boardID = config.Board
+	}
+
+	allProjects, _ := args["--all-projects"].(bool)
+
+	// board defines its own issues, so project is not required
+	boardMode := boardID != 0 && (listMode || sprintMode || backlogMode)
+	if config.ProjectName == "" && !allProjects && !boardMode {
+		fmt.Fprintln(
+			os.Stderr,
+			"project name is empty, "+
+				"you can specify it in config, "+
+				"pass -p flag or just specify an issue",
+		)
+		os.Exit(1)
+	}
+
 	switch {
+	case subtaskMode:
+		if args["add"].(bool) {
+			var (
+				issueType, _    = args["<issuetype>"].(string)
+				templateName, _ = args["--template"].(string)
+				options         createOptions
+			)
+
+			options, err = getCreateOptions(args)
+			if err != nil {
+				break
+			}
+
+			err = handleSubtaskCreateMode(
+				issue, issueType, config, templateName, options,
+			)
+			break
+		}
+
+		var (
+			showName       = args["--show-name"].(bool)
+			onlySummary, _ = args["--only-summary"].(bool)
+		)
+
+		err = handleSubtaskListMode(issue, config, showName, onlySummary)
+
+	case editMode:
+		err = handleEditMode(issue)
+
 	case renameMode:
-		title := args["<title>"].(string)
+		var (
+			title  = args["<title>"].(string)
+			dryRun = args["--dry-run"].(bool)
+		)
 
-		err = handleRenameMode(issue, title)
+		if issue != nil {
+			err = handleRenameMode(issue, title, dryRun)
+			break
+		}
+
+		var (
+			query, _       = args["--query"].(string)
+			rawFilterID, _ = args["-f"].(string)
+			filterID, _    = strconv.Atoi(rawFilterID)
+			confirmed      = args["--yes"].(bool)
+			jobs           int
+			jql            string
+		)
+
+		jobs, err = getBulkJobs(args)
+		if err != nil {
+			break
+		}
+
+		jql, err = getQueryJQL(filterID, query, config.ProjectName)
+		if err != nil {
+			break
+		}
+
+		err = handleRenameQueryMode(jql, title, dryRun, config, jobs, confirmed)
 
 	case startMode:
 		err = handleStartMode(issueKey, hooks)
@@ -172,50 +406,279 @@ func main() {
 			commentID = args["<comment>"].(string)
 		}
 
-		err = handleCommentsMode(issue, listMode, deleteMode, commentID)
+		raw, _ := args["--raw"].(bool)
+
+		err = handleCommentsMode(issue, listMode, deleteMode, commentID, raw)
 
 	case listMode:
 		if issue != nil {
-			err = displayIssue(issue)
+			var (
+				raw, _             = args["--raw"].(bool)
+				rawLastComments, _ = args["--last-comments"].(string)
+				lastComments, _    = strconv.Atoi(rawLastComments)
+				withSubtasks, _    = args["--with-subtasks"].(bool)
+			)
+
+			err = displayIssue(
+				issue, config.IssueTemplate, lastComments, raw, withSubtasks,
+			)
 			break
 		}
 
 		var (
 			kanbanMode     = args["--kanban"].(bool)
-			rawLimit, _    = args["-c"].(string)
 			rawFilterID, _ = args["-f"].(string)
-			limit, _       = strconv.Atoi(rawLimit)
 			filterID, _    = strconv.Atoi(rawFilterID)
+			limit          = getLimit(args, defaultLimit)
 			showName       = args["--show-name"].(bool)
 			onlyMy         = args["--my"].(bool)
 			query, _       = args["--query"].(string)
 			order, _       = args["--order"].(string)
 			showSummary, _ = args["--show-summary"].(bool)
 			onlySummary, _ = args["--only-summary"].(bool)
+			rawFilter, _   = args["--raw-filter"].(bool)
+			swimlane, _    = args["--swimlane"].(string)
+			wipCheck, _    = args["--wip-check"].(bool)
+			layout, _      = args["--layout"].(string)
+			wrap, _        = args["--wrap"].(bool)
 		)
 
+		// board is shown the same way as in web interface, with all issues
+		if boardID != 0 {
+			limit = getLimit(args, 0)
+		}
+
+		if epicKey != "" {
+			var epicQuery string
+			epicQuery, err = getEpicChildrenJQL(epicKey)
+			if err != nil {
+				break
+			}
+
+			if query != "" {
+				epicQuery += " AND (" + query + ")"
+			}
+
+			query = epicQuery
+		}
+
+		var filters quickFilters
+		filters, err = getQuickFilters(args)
+		if err != nil {
+			break
+		}
+
 		err = handleListMode(
 			filterID,
+			rawFilter,
+			boardID,
+			sprintName,
 			limit,
 			kanbanMode,
 			config,
 			showName,
-			showSummary,
 			onlyMy,
 			onlySummary,
 			query,
 			order,
+			filters,
+			kanbanOptions{
+				showSummary: showSummary,
+				showName:    showName,
+				swimlane:    swimlane,
+				layout:      layout,
+				wrap:        wrap,
+				template:    config.Workflow.KanbanTemplate,
+				unmapped:    config.Workflow.KanbanUnmapped,
+			},
+			wipCheck,
 		)
 
 	case moveMode:
-		transition, _ := args["<transition>"].(string)
+		var (
+			transition, _ = args["<transition>"].(string)
+			options       transitionOptions
+		)
+
+		options, err = getTransitionOptions(args)
+		if err != nil {
+			break
+		}
+
+		err = handleMoveMode(issue, transition, config.Workflow, options)
+
+	case createMode && args["--last-draft"].(bool):
+		var options createOptions
+		options, err = getCreateOptions(args)
+		if err != nil {
+			break
+		}
 
-		err = handleMoveMode(issue, transition)
+		err = handleLastDraftMode(options)
 
 	case createMode:
 		issueType, _ := args["<issuetype>"].(string)
 		project, _ := args["<project>"].(string)
-		err = handleCreateMode(project, issueType)
+		templateName, _ := args["--template"].(string)
+
+		template, ok := config.GetCreateTemplate(templateName, project, issueType)
+		if !ok && templateName != "" {
+			err = fmt.Errorf("create template not found: %s", templateName)
+			break
+		}
+
+		var options createOptions
+		options, err = getCreateOptions(args)
+		if err != nil {
+			break
+		}
+
+		err = handleCreateMode(project, issueType, "", template.Template, options)
+
+	case sprintMode:
+		if boardID == 0 {
+			err = errNoBoard
+			break
+		}
+
+		sprint, _ := args["<sprint>"].(string)
+
+		switch {
+		case args["add"].(bool):
+			err = handleSprintMoveMode(boardID, sprint, issueKeys)
+		case args["remove"].(bool):
+			err = handleSprintMoveMode(boardID, "", issueKeys)
+		case args["summary"].(bool):
+			err = handleSprintSummaryMode(boardID, sprint)
+		default:
+			states, _ := args["--state"].(string)
+			err = handleSprintListMode(boardID, states)
+		}
+
+	case backlogMode:
+		if boardID == 0 {
+			err = errNoBoard
+			break
+		}
+
+		if args["rank"].(bool) {
+			var position, target string
+			switch {
+			case args["--top"].(bool):
+				position = rankTop
+			case args["--bottom"].(bool):
+				position = rankBottom
+			case args["--before"] != nil:
+				position = rankBefore
+				target = getIssueKey(args["--before"].(string), config.ProjectName)
+			default:
+				position = rankAfter
+				target = getIssueKey(args["--after"].(string), config.ProjectName)
+			}
+
+			err = handleRankMode(boardID, issueKeys, position, target)
+			break
+		}
+
+		var (
+			limit          = getLimit(args, defaultLimit)
+			query, _       = args["--query"].(string)
+			onlyMy         = args["--my"].(bool)
+			showName       = args["--show-name"].(bool)
+			onlySummary, _ = args["--only-summary"].(bool)
+		)
+
+		err = handleBacklogMode(
+			boardID,
+			config,
+			query,
+			onlyMy,
+			limit,
+			showName,
+			onlySummary,
+		)
+
+	case epicMode:
+		switch {
+		case args["add"].(bool):
+			err = handleEpicMoveMode(epicKey, issueKeys)
+		case args["remove"].(bool):
+			err = handleEpicMoveMode("", issueKeys)
+		default:
+			var (
+				query, _ = args["--query"].(string)
+				limit    = getLimit(args, defaultLimit)
+			)
+
+			err = handleEpicListMode(config, query, limit)
+		}
+
+	case bulkMode:
+		var (
+			query, _       = args["--query"].(string)
+			rawFilterID, _ = args["-f"].(string)
+			filterID, _    = strconv.Atoi(rawFilterID)
+			confirmed      = args["--yes"].(bool)
+			action         bulkAction
+			jobs           int
+			jql            string
+			issues         []Issue
+			failures       []bulkFailure
+		)
+
+		action, err = getBulkAction(args, config)
+		if err != nil {
+			break
+		}
+
+		jobs, err = getBulkJobs(args)
+		if err != nil {
+			break
+		}
+
+		// issue keys are read from stdin if query is not specified
+		if query != "" || filterID != 0 {
+			jql, err = getQueryJQL(filterID, query, config.ProjectName)
+			if err != nil {
+				break
+			}
+		}
+
+		issues, failures, err = getBulkIssues(jql, config.ProjectName)
+		if err != nil {
+			break
+		}
+
+		err = handleBulkMode(issues, failures, action, config, jobs, confirmed)
+
+	case setMode:
+		var (
+			field = args["<field>"].(string)
+			value = args["<value>"].(string)
+		)
+
+		err = handleSetMode(field, value, issueKeys)
+
+	case searchMode:
+		var (
+			terms, _       = args["<terms>"].([]string)
+			limit          = getLimit(args, defaultLimit)
+			order, _       = args["--order"].(string)
+			showName       = args["--show-name"].(bool)
+			onlySummary, _ = args["--only-summary"].(bool)
+			inComments, _  = args["--in-comments"].(bool)
+		)
+
+		err = handleSearchMode(
+			terms,
+			config,
+			allProjects,
+			inComments,
+			limit,
+			order,
+			showName,
+			onlySummary,
+		)
 	}
 
 	if err != nil {
@@ -224,28 +687,61 @@ func main() {
 	}
 }
 
+// getIssueKey returns issue key with project prefix, key can be specified
+// as issue number only.
+func getIssueKey(key string, project string) string {
+	if !strings.Contains(key, "-") && project != "" {
+		return project + "-" + key
+	}
+
+	return key
+}
+
+// getLimit returns amount of issues specified by -c or default amount if
+// -c is not specified, zero means no limit.
+func getLimit(args map[string]interface{}, defaultLimit int) int {
+	rawLimit, ok := args["-c"].(string)
+	if !ok {
+		return defaultLimit
+	}
+
+	limit, _ := strconv.Atoi(rawLimit)
+
+	return limit
+}
+
 func handleListMode(
 	filterID int,
+	rawFilter bool,
+	boardID int,
+	sprint string,
 	limit int,
 	kanbanMode bool,
 	config *Configuration,
 	showName bool,
-	showSummary bool,
 	onlyMy bool,
 	onlySummary bool,
 	query string,
 	order string,
+	filters quickFilters,
+	kanbanOptions kanbanOptions,
+	wipCheck bool,
 ) error {
 	var (
-		search *gojira.JiraSearchIssues
-		err    error
+		search  *searchResult
+		sprints []agileSprint
+		err     error
 	)
 
 	if filterID == 0 {
 		filterID = config.Filter
 	}
 
-	if filterID != 0 {
+	if sprint != "" && boardID == 0 {
+		return errNoBoard
+	}
+
+	if filterID != 0 && rawFilter {
 		search, err = searchIssuesByFilterID(filterID)
 		if err != nil {
 			return karma.Format(
@@ -253,9 +749,36 @@ func handleListMode(
 				"unable to search issues by filter: %d", filterID,
 			)
 		}
+
+		search.Issues = filters.Filter(search.Issues, false)
 	} else {
 		chunks := []string{}
 
+		defaultOrder := "updated DESC"
+		if boardID != 0 {
+			// issues of board are sorted by rank by default
+			defaultOrder = ""
+		}
+
+		if filterID != 0 {
+			filter, err := getFilter(filterID)
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to get filter: %d", filterID,
+				)
+			}
+
+			conditions, filterOrder := splitOrderBy(filter.JQL)
+			if conditions != "" {
+				chunks = append(chunks, "("+conditions+")")
+			}
+
+			if filterOrder != "" {
+				defaultOrder = filterOrder
+			}
+		}
+
 		if query != "" {
 			chunks = append(chunks, "("+query+")")
 		}
@@ -264,23 +787,61 @@ func handleListMode(
 			chunks = append(chunks, "assignee = currentUser()")
 		}
 
-		chunks = append(chunks, "project = "+config.ProjectName)
+		chunks = append(chunks, filters.JQL()...)
+
+		if boardID == 0 {
+			chunks = append(chunks, "project = "+config.ProjectName)
+		}
 
 		jql := strings.Join(chunks, " AND ")
 
 		if order != "" {
 			jql += " ORDER BY " + order
-		} else {
-			jql += " ORDER BY updated DESC"
+		} else if defaultOrder != "" {
+			jql += " ORDER BY " + defaultOrder
 		}
 
-		search, err = getIssues(jql, limit)
-		if err != nil {
-			return karma.Format(
-				err,
-				"unable to search issues by project: %s", config.ProjectName,
+		jql = strings.TrimSpace(jql)
+
+		extraFields := []string{}
+		if kanbanMode && kanbanOptions.swimlane == "epic" {
+			epicField, ok, err := findFieldByType(fieldTypeEpicLink)
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to find epic link field",
+				)
+			}
+
+			if ok {
+				extraFields = append(extraFields, epicField.ID)
+			}
+
+			extraFields = append(extraFields, "parent")
+		}
+
+		if boardID != 0 {
+			search = &searchResult{}
+			search.Issues, sprints, err = getAgileBoardIssues(
+				boardID, sprint, jql, limit, extraFields...,
 			)
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to get issues of board: %d", boardID,
+				)
+			}
+		} else {
+			search, err = getIssues(jql, limit, extraFields...)
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to search issues by project: %s", config.ProjectName,
+				)
+			}
 		}
+
+		search.Issues = filters.Filter(search.Issues, true)
 	}
 
 	activeIssueKey, err := getActiveIssueKey()
@@ -288,35 +849,198 @@ func handleListMode(
 		return err
 	}
 
+	for _, sprint := range sprints {
+		fmt.Printf("Sprint: %s", sprint.Name)
+		if sprint.EndDate != "" {
+			fmt.Printf(" (ends %s)", formatTime(sprint.EndDate))
+		}
+
+		fmt.Println()
+	}
+
 	if kanbanMode {
 		workflowStages := config.Workflow.Stages
+		if boardID != 0 {
+			workflowStages, err = getAgileBoardStages(boardID)
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to get configuration of board: %d", boardID,
+				)
+			}
+
+			// card templates are still taken from workflow configuration
+			for index, stage := range workflowStages {
+				for _, configured := range config.Workflow.Stages {
+					if configured.Name == stage.Name {
+						workflowStages[index].KanbanTemplate = configured.KanbanTemplate
+					}
+				}
+			}
+		}
+
 		sort.Sort(KanbanOrderSortableStages(workflowStages))
 
-		board, err := NewKanbanBoard(search.Issues, workflowStages, showSummary, showName)
+		board, err := NewKanbanBoard(search.Issues, workflowStages, kanbanOptions)
 		if err != nil {
 			return err
 		}
 
-		board.GenerateBoardData(activeIssueKey)
+		err = board.GenerateBoardData(activeIssueKey)
+		if err != nil {
+			return err
+		}
 
-		board.Display()
+		err = board.Display()
+		if err != nil {
+			return err
+		}
+
+		if wipCheck {
+			violations := board.GetWIPViolations()
+			if len(violations) > 0 {
+				return fmt.Errorf(
+					"WIP limits are violated: %s",
+					strings.Join(violations, "; "),
+				)
+			}
+		}
 
 		return nil
 	} else {
+		issues := search.Issues
+		if boardID == 0 {
+			issues = sortIssuesByStatus(issues, config.Workflow.Stages)
+		}
+
 		return displayIssues(
-			sortIssuesByStatus(search.Issues, config.Workflow.Stages),
+			issues,
 			activeIssueKey, showName, onlySummary,
 			config.Workflow,
+			nil,
+			nil,
+		)
+	}
+}
+
+func handleSearchMode(
+	terms []string,
+	config *Configuration,
+	allProjects bool,
+	inComments bool,
+	limit int,
+	order string,
+	showName bool,
+	onlySummary bool,
+) error {
+	field := "text"
+	if inComments {
+		field = "comment"
+	}
+
+	jql := field + " ~ " + quoteJQL(strings.Join(terms, " "))
+
+	if !allProjects {
+		jql += " AND project = " + config.ProjectName
+	}
+
+	if order != "" {
+		jql += " ORDER BY " + order
+	} else {
+		jql += " ORDER BY updated DESC"
+	}
+
+	search, err := getIssues(jql, limit)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to search issues: %s", jql,
 		)
 	}
+
+	activeIssueKey, err := getActiveIssueKey()
+	if err != nil {
+		return err
+	}
+
+	return displayIssues(
+		search.Issues,
+		activeIssueKey, showName, onlySummary,
+		config.Workflow,
+		getHighlightPattern(terms),
+		nil,
+	)
+}
+
+func getCreateOptions(args map[string]interface{}) (createOptions, error) {
+	rawFields, err := getCreateRawFields(args)
+	if err != nil {
+		return createOptions{}, err
+	}
+
+	var (
+		summary, _     = args["--summary"].(string)
+		description, _ = args["--description"].(string)
+		printJSON, _   = args["--json"].(bool)
+	)
+
+	return createOptions{
+		summary:     summary,
+		description: description,
+		json:        printJSON,
+		rawFields:   rawFields,
+		fields:      getCreateFields(args),
+	}, nil
+}
+
+func getQuickFilters(args map[string]interface{}) (quickFilters, error) {
+	filters := quickFilters{}
+
+	options := []struct {
+		option string
+		field  string
+	}{
+		{"--status", "status"},
+		{"--assignee", "assignee"},
+		{"--label", "labels"},
+		{"--type", "issuetype"},
+		{"--priority", "priority"},
+	}
+
+	for _, option := range options {
+		expression, ok := args[option.option].(string)
+		if !ok {
+			continue
+		}
+
+		filter, err := newQuickFilter(option.field, expression)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	if expression, ok := args["--text"].(string); ok {
+		filter, err := newTextQuickFilter(expression)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
 }
 
 func handleMoveMode(
-	issue *gojira.Issue,
+	issue *Issue,
 	transition string,
+	workflow Workflow,
+	options transitionOptions,
 ) error {
 	if transition == "" {
-		transitions, err := issue.GetTransitions()
+		transitions, err := getTransitions(issue.Key)
 		if err != nil {
 			return err
 		}
@@ -324,25 +1048,41 @@ func handleMoveMode(
 		return displayTransitions(transitions)
 	}
 
-	transitionRequest := map[string]interface{}{
-		"transition": map[string]string{
-			"id": transition,
-		},
-	}
-
-	jsonedRequest, err := json.Marshal(transitionRequest)
+	moved, err := transitionIssue(issue, transition, workflow, options)
 	if err != nil {
 		return err
 	}
 
-	err = issue.SetTransition(bytes.NewBuffer(jsonedRequest))
+	fmt.Printf("Issue %s moved to %s\n", issue.Key, moved.To.Name)
+
+	return nil
+}
+
+// getTransitionOptions returns values of transition screen fields and
+// comment specified by flags.
+func getTransitionOptions(
+	args map[string]interface{},
+) (transitionOptions, error) {
+	fields, err := getCreateRawFields(args)
 	if err != nil {
-		return err
+		return transitionOptions{}, err
 	}
 
-	fmt.Printf("Issue %s moved\n", issue.Key)
+	if resolution, ok := args["--resolution"].(string); ok {
+		fields["resolution"] = resolution
+	}
 
-	return nil
+	if versions, ok := args["--fix-version"].(string); ok {
+		fields["fixVersions"] = versions
+	}
+
+	comment, _ := args["--comment"].(string)
+
+	return transitionOptions{
+		fields:  fields,
+		comment: comment,
+		prompt:  true,
+	}, nil
 }
 
 func handleTerminateMode(
@@ -393,7 +1133,7 @@ func handleStartMode(
 	return nil
 }
 
-func handleDeleteMode(issue *gojira.Issue) error {
+func handleDeleteMode(issue *Issue) error {
 	err := issue.Delete()
 	if err != nil {
 		return err
@@ -405,7 +1145,7 @@ func handleDeleteMode(issue *gojira.Issue) error {
 }
 
 func handleAssignMode(
-	issue *gojira.Issue,
+	issue *Issue,
 	username string,
 ) error {
 	err := issue.Assignee(username)
@@ -419,7 +1159,10 @@ func handleAssignMode(
 }
 
 func handleCommentsMode(
-	issue *gojira.Issue, listMode, deleteMode bool, rawCommentID string,
+	issue *Issue,
+	listMode, deleteMode bool,
+	rawCommentID string,
+	raw bool,
 ) error {
 	switch {
 	case deleteMode:
@@ -438,12 +1181,15 @@ func handleCommentsMode(
 		return nil
 
 	case listMode:
-		comments, err := issue.GetComments()
+		comments, err := getIssueComments(issue.Key)
 		if err != nil {
-			return err
+			return karma.Format(
+				err,
+				"unable to get comments of issue: %s", issue.Key,
+			)
 		}
 
-		return displayComments(comments)
+		return displayComments(comments, raw)
 
 	default:
 		err := addComment(issue)
@@ -456,27 +1202,3 @@ func handleCommentsMode(
 		return nil
 	}
 }
-
-func handleRenameMode(
-	issue *gojira.Issue, title string,
-) error {
-	if strings.HasPrefix(title, "s/") {
-		cmd := exec.Command("sed", "-r", title)
-		cmd.Stdin = bytes.NewBufferString(issue.Fields.Summary)
-		seded, _, err := executil.Run(cmd)
-		if err != nil {
-			return err
-		}
-
-		title = string(seded)
-	}
-
-	err := issue.SetSummary(title)
-	if err != nil {
-		return err
-	}
-
-	fmt.Println(issue.Key + " successfully renamed to: " + title)
-
-	return nil
-}
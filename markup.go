@@ -0,0 +1,696 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/reconquest/loreley"
+	"golang.org/x/term"
+)
+
+const defaultTerminalWidth = 80
+
+var (
+	reMarkupBlock      = regexp.MustCompile(`^\s*\{(code|noformat)(?::[^}]*)?\}(.*)$`)
+	reMarkupHeading    = regexp.MustCompile(`^\s*h([1-6])\.\s+(.*)$`)
+	reMarkupList       = regexp.MustCompile(`^\s*([*#-]+)\s+(.*)$`)
+	reMarkupBlockQuote = regexp.MustCompile(`^\s*bq\.\s+(.*)$`)
+	reMarkupRule       = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	reMarkupPanel      = regexp.MustCompile(`\{panel(?::[^}]*)?\}`)
+
+	reMarkupMonospace = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	reMarkupMention   = regexp.MustCompile(`\[~([^\]]+)\]`)
+	reMarkupLink      = regexp.MustCompile(`\[([^\]|]+)\|([^\]]+)\]`)
+	reMarkupURL       = regexp.MustCompile(`\[([a-z]+://[^\]]+)\]`)
+	reMarkupImage     = regexp.MustCompile(`!([^!\s|]+\.[a-zA-Z0-9]+)(?:\|[^!]*)?!`)
+	reMarkupColor     = regexp.MustCompile(`\{color(?::[^}]*)?\}`)
+	reMarkupBold      = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*([^\w*]|$)`)
+	reMarkupItalic    = regexp.MustCompile(`(^|[^\w_])_([^_\s](?:[^_]*[^_\s])?)_([^\w_]|$)`)
+	reMarkupInserted  = regexp.MustCompile(`(^|[^\w+])\+([^+\s](?:[^+]*[^+\s])?)\+([^\w+]|$)`)
+
+	reLoreleyStyle = regexp.MustCompile(`<[a-z][^<>]*>`)
+	reLoreleyToken = regexp.MustCompile(`^(?:<"<">|<[a-z][^<>]*>)`)
+)
+
+// markupRenderer renders Jira wiki markup into loreley styled text wrapped
+// to specified width.
+type markupRenderer struct {
+	width  int
+	lines  []string
+	quote  bool
+	table  [][]string
+	counts map[int]int
+}
+
+func getTerminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+
+	return width
+}
+
+// isColorized returns true if output should be colored the same way as
+// loreley does it.
+func isColorized() bool {
+	switch loreley.Colorize {
+	case loreley.ColorizeAlways:
+		return true
+	case loreley.ColorizeOnTTY:
+		return loreley.HasTTY(int(os.Stdout.Fd()))
+	default:
+		return false
+	}
+}
+
+func colorize(text string) (string, error) {
+	loreley.DelimLeft = "<"
+	loreley.DelimRight = ">"
+
+	return loreley.CompileAndExecuteToString(text, nil, nil)
+}
+
+// formatMarkup returns Jira field value as text: string as is and
+// Atlassian Document Format as indented JSON.
+func formatMarkup(source interface{}) string {
+	switch value := source.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		encoded, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+
+		return string(encoded)
+	}
+}
+
+// renderMarkup renders Jira wiki markup or Atlassian Document Format
+// document into loreley styled text wrapped to specified width.
+func renderMarkup(source interface{}, width int) string {
+	var text string
+	switch value := source.(type) {
+	case nil:
+		return ""
+	case string:
+		text = value
+	case map[string]interface{}:
+		text = convertADF(value)
+	default:
+		return escapeLoreley(formatMarkup(source))
+	}
+
+	renderer := &markupRenderer{
+		width:  width,
+		counts: map[int]int{},
+	}
+
+	renderer.render(text)
+
+	return strings.Join(renderer.lines, "\n")
+}
+
+func (renderer *markupRenderer) render(text string) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := reMarkupPanel.ReplaceAllString(lines[i], "")
+
+		if matches := reMarkupBlock.FindStringSubmatch(line); matches != nil {
+			renderer.flushTable()
+			i = renderer.renderBlock(lines, i, matches[1], matches[2])
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(line), "|") {
+			renderer.addTableRow(line)
+			continue
+		}
+
+		renderer.flushTable()
+
+		if strings.Contains(line, "{quote}") {
+			for index, part := range strings.Split(line, "{quote}") {
+				if index > 0 {
+					renderer.quote = !renderer.quote
+				}
+
+				if strings.TrimSpace(part) != "" {
+					renderer.renderLine(part)
+				}
+			}
+
+			continue
+		}
+
+		renderer.renderLine(line)
+	}
+
+	renderer.flushTable()
+
+	for len(renderer.lines) > 0 && renderer.lines[len(renderer.lines)-1] == "" {
+		renderer.lines = renderer.lines[:len(renderer.lines)-1]
+	}
+}
+
+// renderBlock renders {code} or {noformat} block which starts at specified
+// line and returns index of the line where the block ends.
+func (renderer *markupRenderer) renderBlock(
+	lines []string,
+	start int,
+	tag string,
+	rest string,
+) int {
+	closing := "{" + tag + "}"
+
+	contents := []string{}
+
+	i := start
+	line := rest
+	for {
+		if index := strings.Index(line, closing); index >= 0 {
+			if strings.TrimSpace(line[:index]) != "" {
+				contents = append(contents, line[:index])
+			}
+
+			break
+		}
+
+		if i > start || strings.TrimSpace(line) != "" {
+			contents = append(contents, line)
+		}
+
+		i++
+		if i >= len(lines) {
+			break
+		}
+
+		line = lines[i]
+	}
+
+	for _, line := range contents {
+		renderer.add("    <fg 2>" + escapeLoreley(line) + "<nofg>")
+	}
+
+	return i
+}
+
+func (renderer *markupRenderer) renderLine(line string) {
+	for _, part := range strings.Split(line, `\\`) {
+		renderer.renderPart(part)
+	}
+}
+
+func (renderer *markupRenderer) renderPart(line string) {
+	if strings.TrimSpace(line) == "" {
+		renderer.counts = map[int]int{}
+
+		if len(renderer.lines) > 0 &&
+			renderer.lines[len(renderer.lines)-1] != "" {
+			renderer.add("")
+		}
+
+		return
+	}
+
+	if reMarkupRule.MatchString(line) {
+		renderer.add(strings.Repeat("─", renderer.getWidth()))
+		return
+	}
+
+	if matches := reMarkupHeading.FindStringSubmatch(line); matches != nil {
+		style := "<bold>"
+		if matches[1] == "1" || matches[1] == "2" {
+			style += "<underline>"
+		}
+
+		renderer.wrap(
+			style+renderInlineMarkup(matches[2])+"<reset>", "", "",
+		)
+
+		return
+	}
+
+	if matches := reMarkupBlockQuote.FindStringSubmatch(line); matches != nil {
+		renderer.wrap(renderInlineMarkup(matches[1]), "│ ", "│ ")
+		return
+	}
+
+	if matches := reMarkupList.FindStringSubmatch(line); matches != nil {
+		depth := len(matches[1])
+		indent := strings.Repeat("  ", depth-1)
+
+		for level := range renderer.counts {
+			if level > depth {
+				delete(renderer.counts, level)
+			}
+		}
+
+		bullet := "• "
+		if strings.HasSuffix(matches[1], "#") {
+			renderer.counts[depth]++
+			bullet = strconv.Itoa(renderer.counts[depth]) + ". "
+		}
+
+		renderer.wrap(
+			renderInlineMarkup(matches[2]),
+			indent+bullet,
+			indent+strings.Repeat(" ", len(bullet)),
+		)
+
+		return
+	}
+
+	renderer.counts = map[int]int{}
+
+	renderer.wrap(renderInlineMarkup(strings.TrimRight(line, " ")), "", "")
+}
+
+func (renderer *markupRenderer) addTableRow(line string) {
+	line = strings.TrimSpace(line)
+
+	header := strings.HasPrefix(line, "||")
+
+	separator := "|"
+	if header {
+		separator = "||"
+	}
+
+	line = strings.TrimSuffix(strings.TrimPrefix(line, separator), separator)
+
+	cells := []string{}
+	for _, cell := range strings.Split(line, separator) {
+		cell = renderInlineMarkup(strings.TrimSpace(strings.Trim(cell, "|")))
+		if header {
+			cell = "<bold>" + cell + "<nobold>"
+		}
+
+		cells = append(cells, cell)
+	}
+
+	renderer.table = append(renderer.table, cells)
+}
+
+func (renderer *markupRenderer) flushTable() {
+	if len(renderer.table) == 0 {
+		return
+	}
+
+	widths := []int{}
+	for _, row := range renderer.table {
+		for column, cell := range row {
+			if column >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if width := getVisibleWidth(cell); width > widths[column] {
+				widths[column] = width
+			}
+		}
+	}
+
+	for _, row := range renderer.table {
+		cells := []string{}
+		for column, cell := range row {
+			cells = append(
+				cells,
+				cell+strings.Repeat(" ", widths[column]-getVisibleWidth(cell)),
+			)
+		}
+
+		renderer.add("│ " + strings.Join(cells, " │ ") + " │")
+	}
+
+	renderer.table = nil
+}
+
+func (renderer *markupRenderer) getWidth() int {
+	width := renderer.width
+	if renderer.quote {
+		width -= 2
+	}
+
+	if width < 20 {
+		width = 20
+	}
+
+	return width
+}
+
+func (renderer *markupRenderer) add(line string) {
+	if renderer.quote {
+		line = "│ " + line
+	}
+
+	renderer.lines = append(renderer.lines, line)
+}
+
+// wrap splits styled text into lines which visible width fits renderer
+// width, first line gets firstPrefix and the rest get restPrefix.
+func (renderer *markupRenderer) wrap(text, firstPrefix, restPrefix string) {
+	width := renderer.getWidth()
+
+	line := firstPrefix
+	lineWidth := getVisibleWidth(firstPrefix)
+	empty := true
+
+	for _, word := range splitStyledWords(text) {
+		wordWidth := getVisibleWidth(word)
+
+		if !empty && lineWidth+1+wordWidth > width {
+			renderer.add(line)
+
+			line = restPrefix
+			lineWidth = getVisibleWidth(restPrefix)
+			empty = true
+		}
+
+		if !empty {
+			line += " "
+			lineWidth++
+		}
+
+		line += word
+		lineWidth += wordWidth
+		empty = false
+	}
+
+	renderer.add(line)
+}
+
+// renderInlineMarkup converts inline Jira markup into loreley styles.
+func renderInlineMarkup(text string) string {
+	text = escapeLoreley(text)
+
+	monospaces := []string{}
+	text = reMarkupMonospace.ReplaceAllStringFunc(
+		text,
+		func(match string) string {
+			monospaces = append(
+				monospaces,
+				"<fg 3>"+reMarkupMonospace.FindStringSubmatch(match)[1]+"<nofg>",
+			)
+
+			return fmt.Sprintf("\x00%d\x00", len(monospaces)-1)
+		},
+	)
+
+	text = reMarkupMention.ReplaceAllString(text, "<fg 4>@${1}<nofg>")
+	text = reMarkupLink.ReplaceAllString(
+		text, "<underline>${1}<nounderline> (${2})",
+	)
+	text = reMarkupURL.ReplaceAllString(text, "<underline>${1}<nounderline>")
+	text = reMarkupImage.ReplaceAllString(text, "[image: ${1}]")
+	text = reMarkupColor.ReplaceAllString(text, "")
+
+	// replacing twice because adjacent matches share the boundary symbol
+	for i := 0; i < 2; i++ {
+		text = reMarkupBold.ReplaceAllString(text, "${1}<bold>${2}<nobold>${3}")
+		text = reMarkupItalic.ReplaceAllString(
+			text, "${1}<underline>${2}<nounderline>${3}",
+		)
+		text = reMarkupInserted.ReplaceAllString(
+			text, "${1}<underline>${2}<nounderline>${3}",
+		)
+	}
+
+	for i, monospace := range monospaces {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), monospace, 1)
+	}
+
+	return text
+}
+
+// getVisibleWidth returns width of loreley styled text as it will be
+// displayed in terminal.
+func getVisibleWidth(text string) int {
+	text = strings.ReplaceAll(text, `<"<">`, "<")
+	text = reLoreleyStyle.ReplaceAllString(text, "")
+
+	return runewidth.StringWidth(text)
+}
+
+// splitStyledWords splits loreley styled text by spaces which are not part
+// of styles like <fg 1>.
+func splitStyledWords(text string) []string {
+	words := []string{}
+	word := &strings.Builder{}
+
+	for text != "" {
+		if token := reLoreleyToken.FindString(text); token != "" {
+			word.WriteString(token)
+			text = text[len(token):]
+
+			continue
+		}
+
+		if text[0] == ' ' {
+			words = append(words, word.String())
+			word.Reset()
+			text = text[1:]
+
+			continue
+		}
+
+		symbol, size := utf8.DecodeRuneInString(text)
+		word.WriteRune(symbol)
+		text = text[size:]
+	}
+
+	return append(words, word.String())
+}
+
+// truncateStyles truncates loreley styled text to specified visible width
+// keeping styles intact.
+func truncateStyles(text string, width int) string {
+	if getVisibleWidth(text) <= width {
+		return text
+	}
+
+	result := &strings.Builder{}
+	visible := 0
+	for text != "" {
+		if token := reLoreleyToken.FindString(text); token != "" {
+			text = text[len(token):]
+
+			if !strings.HasPrefix(token, `<"`) {
+				result.WriteString(token)
+				continue
+			}
+
+			if visible+1 > width-1 {
+				break
+			}
+
+			result.WriteString(token)
+			visible++
+
+			continue
+		}
+
+		symbol, size := utf8.DecodeRuneInString(text)
+		if visible+runewidth.RuneWidth(symbol) > width-1 {
+			break
+		}
+
+		result.WriteRune(symbol)
+		visible += runewidth.RuneWidth(symbol)
+		text = text[size:]
+	}
+
+	return result.String() + "…<reset>"
+}
+
+// convertADF converts Atlassian Document Format document into Jira wiki
+// markup, so it can be rendered the same way.
+func convertADF(node map[string]interface{}) string {
+	buffer := &strings.Builder{}
+
+	convertADFBlocks(buffer, getADFContent(node), "")
+
+	return buffer.String()
+}
+
+func convertADFBlocks(
+	buffer *strings.Builder,
+	nodes []map[string]interface{},
+	listPrefix string,
+) {
+	for _, node := range nodes {
+		attrs, _ := node["attrs"].(map[string]interface{})
+
+		switch node["type"] {
+		case "paragraph":
+			if listPrefix != "" {
+				buffer.WriteString(listPrefix + " ")
+			}
+
+			buffer.WriteString(convertADFInline(getADFContent(node)) + "\n")
+
+			if listPrefix == "" {
+				buffer.WriteString("\n")
+			}
+
+		case "heading":
+			level, _ := attrs["level"].(float64)
+			if level == 0 {
+				level = 1
+			}
+
+			fmt.Fprintf(
+				buffer, "h%d. %s\n\n",
+				int(level), convertADFInline(getADFContent(node)),
+			)
+
+		case "bulletList", "orderedList":
+			marker := "*"
+			if node["type"] == "orderedList" {
+				marker = "#"
+			}
+
+			for _, item := range getADFContent(node) {
+				convertADFBlocks(buffer, getADFContent(item), listPrefix+marker)
+			}
+
+			if listPrefix == "" {
+				buffer.WriteString("\n")
+			}
+
+		case "codeBlock":
+			buffer.WriteString(
+				"{code}\n" + convertADFInline(getADFContent(node)) + "\n{code}\n",
+			)
+
+		case "blockquote":
+			buffer.WriteString("{quote}\n")
+			convertADFBlocks(buffer, getADFContent(node), "")
+			buffer.WriteString("{quote}\n")
+
+		case "rule":
+			buffer.WriteString("----\n")
+
+		case "table":
+			for _, row := range getADFContent(node) {
+				cells := []string{}
+				separator := "|"
+				for _, cell := range getADFContent(row) {
+					if cell["type"] == "tableHeader" {
+						separator = "||"
+					}
+
+					texts := []string{}
+					for _, child := range getADFContent(cell) {
+						texts = append(
+							texts, convertADFInline(getADFContent(child)),
+						)
+					}
+
+					cells = append(cells, strings.Join(texts, " "))
+				}
+
+				buffer.WriteString(
+					separator + strings.Join(cells, separator) + separator + "\n",
+				)
+			}
+
+			buffer.WriteString("\n")
+
+		case "mediaSingle", "mediaGroup":
+			for _, media := range getADFContent(node) {
+				mediaAttrs, _ := media["attrs"].(map[string]interface{})
+				name, _ := mediaAttrs["alt"].(string)
+				if name == "" {
+					name, _ = mediaAttrs["id"].(string)
+				}
+
+				buffer.WriteString("[attachment: " + name + "]\n")
+			}
+
+		default:
+			content := getADFContent(node)
+			if len(content) > 0 {
+				convertADFBlocks(buffer, content, listPrefix)
+			} else if text := convertADFInline([]map[string]interface{}{node}); text != "" {
+				buffer.WriteString(text + "\n")
+			}
+		}
+	}
+}
+
+func convertADFInline(nodes []map[string]interface{}) string {
+	buffer := &strings.Builder{}
+
+	for _, node := range nodes {
+		attrs, _ := node["attrs"].(map[string]interface{})
+
+		switch node["type"] {
+		case "text":
+			text, _ := node["text"].(string)
+
+			marks, _ := node["marks"].([]interface{})
+			for _, rawMark := range marks {
+				mark, _ := rawMark.(map[string]interface{})
+				markAttrs, _ := mark["attrs"].(map[string]interface{})
+
+				switch mark["type"] {
+				case "strong":
+					text = "*" + text + "*"
+				case "em":
+					text = "_" + text + "_"
+				case "underline":
+					text = "+" + text + "+"
+				case "code":
+					text = "{{" + text + "}}"
+				case "link":
+					href, _ := markAttrs["href"].(string)
+					text = "[" + text + "|" + href + "]"
+				}
+			}
+
+			buffer.WriteString(text)
+
+		case "hardBreak":
+			buffer.WriteString(`\\`)
+
+		case "mention":
+			text, _ := attrs["text"].(string)
+			buffer.WriteString("[~" + strings.TrimPrefix(text, "@") + "]")
+
+		case "emoji":
+			text, _ := attrs["shortName"].(string)
+			buffer.WriteString(text)
+
+		case "inlineCard":
+			url, _ := attrs["url"].(string)
+			buffer.WriteString("[" + url + "]")
+
+		default:
+			buffer.WriteString(convertADFInline(getADFContent(node)))
+		}
+	}
+
+	return buffer.String()
+}
+
+func getADFContent(node map[string]interface{}) []map[string]interface{} {
+	raw, _ := node["content"].([]interface{})
+
+	nodes := []map[string]interface{}{}
+	for _, item := range raw {
+		if child, ok := item.(map[string]interface{}); ok {
+			nodes = append(nodes, child)
+		}
+	}
+
+	return nodes
+}
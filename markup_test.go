@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRenderInlineMarkup(t *testing.T) {
+	testcases := []struct {
+		text     string
+		rendered string
+	}{
+		{"plain text", "plain text"},
+		{"*bold* text", "<bold>bold<nobold> text"},
+		{"_italic_ and +inserted+", "<underline>italic<nounderline> and <underline>inserted<nounderline>"},
+		{"*one* *two*", "<bold>one<nobold> <bold>two<nobold>"},
+		{"snake_case_name", "snake_case_name"},
+		{"2*3*4", "2*3*4"},
+		{"{{*literal*}}", "<fg 3>*literal*<nofg>"},
+		{"[~john]", "<fg 4>@john<nofg>"},
+		{"[docs|https://example.com]", "<underline>docs<nounderline> (https://example.com)"},
+		{"[https://example.com]", "<underline>https://example.com<nounderline>"},
+		{"!screen.png|thumbnail!", "[image: screen.png]"},
+		{"{color:red}red{color}", "red"},
+		{"a < b", `a <"<"> b`},
+	}
+
+	for _, testcase := range testcases {
+		rendered := renderInlineMarkup(testcase.text)
+		if rendered != testcase.rendered {
+			t.Errorf(
+				"%q: expected %q, got %q",
+				testcase.text, testcase.rendered, rendered,
+			)
+		}
+	}
+}
+
+func TestRenderMarkup(t *testing.T) {
+	testcases := []struct {
+		source   string
+		width    int
+		rendered string
+	}{
+		{
+			source:   "h1. Title\n\ntext",
+			width:    80,
+			rendered: "<bold><underline>Title<reset>\n\ntext",
+		},
+		{
+			source:   "# one\n# two\n## nested\n# three\n* item",
+			width:    80,
+			rendered: "1. one\n2. two\n  1. nested\n3. three\n• item",
+		},
+		{
+			source:   "one two three four five six seven",
+			width:    20,
+			rendered: "one two three four\nfive six seven",
+		},
+		{
+			source:   "bq. quoted",
+			width:    80,
+			rendered: "│ quoted",
+		},
+		{
+			source:   "{quote}\nquoted\n{quote}\nafter",
+			width:    80,
+			rendered: "│ quoted\nafter",
+		},
+		{
+			source:   "{code:go}\nfmt.Println()\n{code}\ntext",
+			width:    80,
+			rendered: "    <fg 2>fmt.Println()<nofg>\ntext",
+		},
+		{
+			source:   "{noformat}*raw*{noformat}",
+			width:    80,
+			rendered: "    <fg 2>*raw*<nofg>",
+		},
+		{
+			source: "||name||value||\n|a|long value|",
+			width:  80,
+			rendered: "│ <bold>name<nobold> │ <bold>value<nobold>      │\n" +
+				"│ a    │ long value │",
+		},
+		{
+			source: "|a|b|\n{code}\ncode\n{code}",
+			width:  80,
+			rendered: "│ a │ b │\n" +
+				"    <fg 2>code<nofg>",
+		},
+		{
+			source: "|a|b|\ntext",
+			width:  80,
+			rendered: "│ a │ b │\n" +
+				"text",
+		},
+	}
+
+	for _, testcase := range testcases {
+		rendered := renderMarkup(testcase.source, testcase.width)
+		if rendered != testcase.rendered {
+			t.Errorf(
+				"%q: expected\n%s\ngot\n%s",
+				testcase.source, testcase.rendered, rendered,
+			)
+		}
+	}
+}
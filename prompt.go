@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ask prints prompt and returns line entered by user. Terminal is used
+// instead of stdin if it's available, because stdin can be used for input
+// data like issue keys.
+func ask(prompt string) (string, error) {
+	input := os.Stdin
+
+	terminal, err := os.Open("/dev/tty")
+	if err == nil {
+		defer terminal.Close()
+
+		input = terminal
+	}
+
+	fmt.Print(prompt)
+
+	answer, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+// confirm asks user for confirmation, only y and yes are accepted.
+func confirm(prompt string) (bool, error) {
+	answer, err := ask(prompt)
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(answer)
+
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// quickFilter is a simple expression like "In progress,Open" or "!Done"
+// which is applied to issues without writing JQL.
+type quickFilter struct {
+	// field is a JQL field name, empty if filter can't be expressed in JQL.
+	field   string
+	values  []string
+	pattern *regexp.Regexp
+	negate  bool
+	get     func(issue Issue) []string
+}
+
+type quickFilters []quickFilter
+
+var quickFilterGetters = map[string]func(issue Issue) []string{
+	"status": func(issue Issue) []string {
+		return []string{issue.Fields.Status.Name}
+	},
+	"assignee": func(issue Issue) []string {
+		return []string{
+			issue.Fields.Assignee.Name,
+			issue.Fields.Assignee.DisplayName,
+		}
+	},
+	"labels": func(issue Issue) []string {
+		return issue.Fields.Labels
+	},
+	"issuetype": func(issue Issue) []string {
+		return []string{issue.Fields.IssueType.Name}
+	},
+	"priority": func(issue Issue) []string {
+		return []string{issue.Extra.Priority.Name}
+	},
+}
+
+// newQuickFilter parses given expression for specified JQL field.
+// Expression is a comma-separated list of values, prefixed with ! to negate
+// it.
+func newQuickFilter(field, expression string) (quickFilter, error) {
+	get, ok := quickFilterGetters[field]
+	if !ok {
+		return quickFilter{}, fmt.Errorf("unknown quick filter field: %s", field)
+	}
+
+	filter := quickFilter{
+		field: field,
+		get:   get,
+	}
+
+	if strings.HasPrefix(expression, "!") {
+		filter.negate = true
+		expression = expression[1:]
+	}
+
+	filter.values = splitList(expression)
+
+	if len(filter.values) == 0 {
+		return quickFilter{}, fmt.Errorf("empty quick filter for %s", field)
+	}
+
+	return filter, nil
+}
+
+// newTextQuickFilter creates filter that matches issue summary against
+// case-insensitive substring or regular expression enclosed in slashes
+// like /^fix/. It can't be expressed in JQL and always applied locally.
+func newTextQuickFilter(expression string) (quickFilter, error) {
+	filter := quickFilter{
+		get: func(issue Issue) []string {
+			return []string{issue.Fields.Summary}
+		},
+	}
+
+	if strings.HasPrefix(expression, "!") {
+		filter.negate = true
+		expression = expression[1:]
+	}
+
+	if len(expression) > 1 &&
+		strings.HasPrefix(expression, "/") &&
+		strings.HasSuffix(expression, "/") {
+		pattern, err := regexp.Compile(expression[1 : len(expression)-1])
+		if err != nil {
+			return quickFilter{}, karma.Format(
+				err,
+				"unable to compile text filter: %s", expression,
+			)
+		}
+
+		filter.pattern = pattern
+	} else {
+		filter.pattern = regexp.MustCompile(
+			"(?i)" + regexp.QuoteMeta(expression),
+		)
+	}
+
+	return filter, nil
+}
+
+func (filter quickFilter) Match(issue Issue) bool {
+	matched := false
+
+	for _, actual := range filter.get(issue) {
+		if filter.pattern != nil {
+			matched = filter.pattern.MatchString(actual)
+		} else {
+			for _, value := range filter.values {
+				if strings.EqualFold(actual, value) {
+					matched = true
+					break
+				}
+			}
+		}
+
+		if matched {
+			break
+		}
+	}
+
+	return matched != filter.negate
+}
+
+func (filter quickFilter) JQL() string {
+	values := []string{}
+	for _, value := range filter.values {
+		values = append(values, quoteJQL(value))
+	}
+
+	if filter.negate {
+		return fmt.Sprintf(
+			"(%s not in (%s) OR %s is EMPTY)",
+			filter.field, strings.Join(values, ", "), filter.field,
+		)
+	}
+
+	return fmt.Sprintf("%s in (%s)", filter.field, strings.Join(values, ", "))
+}
+
+// JQL returns conditions for filters which can be expressed in JQL.
+func (filters quickFilters) JQL() []string {
+	chunks := []string{}
+	for _, filter := range filters {
+		if filter.field != "" {
+			chunks = append(chunks, filter.JQL())
+		}
+	}
+
+	return chunks
+}
+
+// Filter returns issues that match all filters. If onlyLocal is true, then
+// filters which can be expressed in JQL are considered already applied.
+func (filters quickFilters) Filter(issues []Issue, onlyLocal bool) []Issue {
+	if len(filters) == 0 {
+		return issues
+	}
+
+	result := []Issue{}
+
+	for _, issue := range issues {
+		matched := true
+		for _, filter := range filters {
+			if onlyLocal && filter.field != "" {
+				continue
+			}
+
+			if !filter.Match(issue) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			result = append(result, issue)
+		}
+	}
+
+	return result
+}
+
+func quoteJQL(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
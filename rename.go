@@ -0,0 +1,280 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// substitution is a sed-like s/pattern/replacement/flags expression.
+type substitution struct {
+	pattern     *regexp.Regexp
+	replacement string
+	global      bool
+}
+
+// errRenameMany is returned when plain title is used to rename many issues,
+// which would give all of them the same summary.
+var errRenameMany = errors.New(
+	"only s/pattern/replacement/flags expression can be used " +
+		"to rename many issues",
+)
+
+// substitutionDelimiters are symbols which can be used as delimiter of
+// substitution expression instead of /, other symbols are not recognized to
+// keep titles like s-expression as is.
+const substitutionDelimiters = "/|#!@%,:;~"
+
+// isSubstitution returns true if title is a substitution expression.
+func isSubstitution(title string) bool {
+	return len(title) > 1 && title[0] == 's' &&
+		strings.IndexByte(substitutionDelimiters, title[1]) >= 0
+}
+
+// parseSubstitution parses s/pattern/replacement/flags expression, pattern
+// uses Go regexp syntax, replacement can contain backreferences \1 - \9 and
+// & for the whole match. Supported flags are g (replace all matches) and i
+// (case-insensitive match).
+func parseSubstitution(expression string) (*substitution, error) {
+	if len(expression) < 2 || expression[0] != 's' {
+		return nil, fmt.Errorf(
+			"invalid substitution: %s, expected s/pattern/replacement/flags",
+			expression,
+		)
+	}
+
+	delimiter := expression[1]
+
+	parts := []string{}
+	part := []byte{}
+	for i := 2; i < len(expression); i++ {
+		switch {
+		case expression[i] == '\\' && i+1 < len(expression):
+			i++
+			if expression[i] != delimiter {
+				part = append(part, '\\')
+			}
+
+			part = append(part, expression[i])
+
+		case expression[i] == delimiter:
+			parts = append(parts, string(part))
+			part = []byte{}
+
+		default:
+			part = append(part, expression[i])
+		}
+	}
+
+	parts = append(parts, string(part))
+
+	if len(parts) != 3 {
+		return nil, fmt.Errorf(
+			"invalid substitution: %s, expected s/pattern/replacement/flags",
+			expression,
+		)
+	}
+
+	result := &substitution{
+		replacement: getReplacementTemplate(parts[1]),
+	}
+
+	pattern := parts[0]
+	for _, flag := range parts[2] {
+		switch flag {
+		case 'g':
+			result.global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, fmt.Errorf(
+				"invalid substitution flag: %c, expected g or i", flag,
+			)
+		}
+	}
+
+	var err error
+	result.pattern, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"invalid substitution pattern: %s", parts[0],
+		)
+	}
+
+	return result, nil
+}
+
+// getReplacementTemplate converts sed replacement to regexp template, like
+// \1 to ${1} and & to ${0}.
+func getReplacementTemplate(replacement string) string {
+	template := []byte{}
+	for i := 0; i < len(replacement); i++ {
+		switch replacement[i] {
+		case '\\':
+			if i+1 == len(replacement) {
+				template = append(template, '\\')
+				continue
+			}
+
+			i++
+			if replacement[i] >= '0' && replacement[i] <= '9' {
+				template = append(template, "${"+string(replacement[i])+"}"...)
+				continue
+			}
+
+			template = append(template, replacement[i])
+
+		case '&':
+			template = append(template, "${0}"...)
+
+		case '$':
+			template = append(template, "$$"...)
+
+		default:
+			template = append(template, replacement[i])
+		}
+	}
+
+	return string(template)
+}
+
+// Apply returns text with substituted first or all matches of pattern.
+func (substitution *substitution) Apply(text string) string {
+	if substitution.global {
+		return substitution.pattern.ReplaceAllString(
+			text, substitution.replacement,
+		)
+	}
+
+	match := substitution.pattern.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text
+	}
+
+	replaced := substitution.pattern.ExpandString(
+		nil, substitution.replacement, text, match,
+	)
+
+	return text[:match[0]] + string(replaced) + text[match[1]:]
+}
+
+// getNewTitle returns title as is or result of substitution if title is
+// s/pattern/replacement/flags expression.
+func getNewTitle(summary string, title string) (string, error) {
+	if !isSubstitution(title) {
+		return title, nil
+	}
+
+	substitution, err := parseSubstitution(title)
+	if err != nil {
+		return "", err
+	}
+
+	return substitution.Apply(summary), nil
+}
+
+func handleRenameMode(
+	issue *Issue, title string, dryRun bool,
+) error {
+	title, err := getNewTitle(issue.Fields.Summary, title)
+	if err != nil {
+		return err
+	}
+
+	return renameIssue(issue, title, dryRun)
+}
+
+// handleRenameQueryMode applies substitution to summaries of all issues
+// matching the query after confirmation the same way as bulk rename does,
+// with dry run old and new titles of changed issues are only shown.
+func handleRenameQueryMode(
+	jql string,
+	title string,
+	dryRun bool,
+	config *Configuration,
+	jobs int,
+	confirmed bool,
+) error {
+	substitution, err := parseRenameSubstitution(title)
+	if err != nil {
+		return err
+	}
+
+	issues, err := getAllIssues(jql)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to search issues: %s", jql,
+		)
+	}
+
+	if !dryRun {
+		return handleBulkMode(
+			issues, nil, getRenameAction(substitution, title),
+			config, jobs, confirmed,
+		)
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+
+		title := substitution.Apply(issue.Fields.Summary)
+		if title == issue.Fields.Summary {
+			continue
+		}
+
+		err := renameIssue(issue, title, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseRenameSubstitution parses substitution which is used to rename many
+// issues, plain title is not allowed because all issues would get it.
+func parseRenameSubstitution(title string) (*substitution, error) {
+	if !isSubstitution(title) {
+		return nil, errRenameMany
+	}
+
+	return parseSubstitution(title)
+}
+
+// getRenameAction returns bulk action which applies substitution to issue
+// summaries, issues with unchanged summaries are skipped.
+func getRenameAction(substitution *substitution, title string) bulkAction {
+	return bulkAction{
+		description: "rename using " + title,
+		apply: func(issue *Issue) error {
+			title := substitution.Apply(issue.Fields.Summary)
+			if title == issue.Fields.Summary {
+				return nil
+			}
+
+			return issue.SetSummary(title)
+		},
+	}
+}
+
+func renameIssue(issue *Issue, title string, dryRun bool) error {
+	if dryRun {
+		fmt.Printf("%s: %s -> %s\n", issue.Key, issue.Fields.Summary, title)
+
+		return nil
+	}
+
+	err := issue.SetSummary(title)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(issue.Key + " successfully renamed to: " + title)
+
+	return nil
+}
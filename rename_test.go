@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIsSubstitution(t *testing.T) {
+	testcases := []struct {
+		title        string
+		substitution bool
+	}{
+		{"s/foo/bar/", true},
+		{"s|a/b|c|", true},
+		{"s#foo#bar#g", true},
+		{"s", false},
+		{"s/", true},
+		{"New summary", false},
+		{"sprint planning", false},
+		{"s-expression parser", false},
+		{"s.o.s", false},
+		{`s\foo\bar\`, false},
+	}
+
+	for _, testcase := range testcases {
+		substitution := isSubstitution(testcase.title)
+		if substitution != testcase.substitution {
+			t.Errorf(
+				"%q: expected %v, got %v",
+				testcase.title, testcase.substitution, substitution,
+			)
+		}
+	}
+}
+
+func TestSubstitution(t *testing.T) {
+	testcases := []struct {
+		expression string
+		text       string
+		result     string
+		err        bool
+	}{
+		{`s/foo/bar/`, "foo foo", "bar foo", false},
+		{`s/foo/bar/g`, "foo foo", "bar bar", false},
+		{`s/FOO/bar/i`, "foo foo", "bar foo", false},
+		{`s/FOO/bar/gi`, "foo Foo", "bar bar", false},
+		{`s/missing/bar/`, "foo", "foo", false},
+		{`s/^WIP: //`, "WIP: login page", "login page", false},
+		{`s/(\w+) (\w+)/\2 \1/`, "hello world", "world hello", false},
+		{`s/o+/[&]/g`, "foo boo", "f[oo] b[oo]", false},
+		{`s/price/$1/`, "price", "$1", false},
+		{`s/a\/b/c/`, "a/b", "c", false},
+		{`s|a/b|c\|d|`, "a/b", "c|d", false},
+		{`s#foo#bar#g`, "foo foo", "bar bar", false},
+		{`s/foo/\&/`, "foo", "&", false},
+		{`s/\d+/N/g`, "1 and 22", "N and N", false},
+		{`s/foo/bar\/`, "foo", "", true},
+		{`s/foo/bar`, "foo", "", true},
+		{`s/foo/bar/baz/`, "foo", "", true},
+		{`s/foo/bar/x`, "foo", "", true},
+		{`s/(/bar/`, "foo", "", true},
+		{`s`, "foo", "", true},
+	}
+
+	for _, testcase := range testcases {
+		substitution, err := parseSubstitution(testcase.expression)
+		if testcase.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got nil", testcase.expression)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", testcase.expression, err)
+			continue
+		}
+
+		result := substitution.Apply(testcase.text)
+		if result != testcase.result {
+			t.Errorf(
+				"%s: expected %q for %q, got %q",
+				testcase.expression, testcase.result, testcase.text, result,
+			)
+		}
+	}
+}
+
+func TestGetReplacementTemplate(t *testing.T) {
+	testcases := []struct {
+		replacement string
+		template    string
+	}{
+		{"plain", "plain"},
+		{"", ""},
+		{`\1-\2`, "${1}-${2}"},
+		{`\10`, "${1}0"},
+		{"[&]", "[${0}]"},
+		{`\&`, "&"},
+		{"$1", "$$1"},
+		{"${name}", "$${name}"},
+		{`a\\b`, `a\b`},
+		{`\n`, "n"},
+		{`trailing\`, `trailing\`},
+	}
+
+	for _, testcase := range testcases {
+		template := getReplacementTemplate(testcase.replacement)
+		if template != testcase.template {
+			t.Errorf(
+				"%q: expected %q, got %q",
+				testcase.replacement, testcase.template, template,
+			)
+		}
+	}
+}
@@ -2,25 +2,61 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 
+	"github.com/reconquest/karma-go"
 	"github.com/tears-of-noobs/gojira"
 )
 
+// searchFields are the issue fields requested in issue search.
+var searchFields = []string{
+	"key", "summary", "status", "assignee", "priority", "labels", "issuetype",
+	"created", "updated",
+}
+
+type searchResult struct {
+	gojira.SearchHead
+	Issues []Issue `json:"issues"`
+}
+
+// defaultLimit is amount of listed issues if -c is not specified.
+const defaultLimit = 30
+
+// searchPageSize is amount of issues requested at once when all issues
+// matching query are needed, Jira can return less issues per page.
+const searchPageSize = 100
+
 func getIssues(
 	query string,
 	limit int,
-) (*gojira.JiraSearchIssues, error) {
+	extraFields ...string,
+) (*searchResult, error) {
+	return getIssuesPage(query, 0, limit, extraFields...)
+}
+
+func getIssuesPage(
+	query string,
+	startAt int,
+	limit int,
+	extraFields ...string,
+) (*searchResult, error) {
+	fields := append(append([]string{}, searchFields...), extraFields...)
+
 	request := url.QueryEscape(query) +
-		"&fields=key,summary,status,assignee&maxResults=" + strconv.Itoa(limit)
+		"&fields=" + strings.Join(fields, ",") +
+		"&startAt=" + strconv.Itoa(startAt) +
+		"&maxResults=" + strconv.Itoa(limit)
 
 	reply, err := gojira.RawSearch(request)
 	if err != nil {
 		return nil, err
 	}
 
-	var result gojira.JiraSearchIssues
+	var result searchResult
 	err = json.Unmarshal(reply, &result)
 	if err != nil {
 		return nil, err
@@ -29,15 +65,35 @@ func getIssues(
 	return &result, nil
 }
 
+// getAllIssues returns all issues matching query, issues are requested page
+// by page.
+func getAllIssues(query string, extraFields ...string) ([]Issue, error) {
+	issues := []Issue{}
+	for {
+		search, err := getIssuesPage(
+			query, len(issues), searchPageSize, extraFields...,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, search.Issues...)
+
+		if len(search.Issues) == 0 || len(issues) >= search.Total {
+			return issues, nil
+		}
+	}
+}
+
 func searchIssuesByFilterID(
 	filterID int,
-) (*gojira.JiraSearchIssues, error) {
+) (*searchResult, error) {
 	jsonedSearchIssues, err := gojira.FilterSearch(filterID)
 	if err != nil {
 		return nil, err
 	}
 
-	var searchIssues gojira.JiraSearchIssues
+	var searchIssues searchResult
 	err = json.Unmarshal(jsonedSearchIssues, &searchIssues)
 	if err != nil {
 		return nil, err
@@ -45,3 +101,69 @@ func searchIssuesByFilterID(
 
 	return &searchIssues, nil
 }
+
+type filter struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	JQL  string `json:"jql"`
+}
+
+func getFilter(filterID int) (*filter, error) {
+	var result filter
+	err := requestAPI("GET", "/filter/"+strconv.Itoa(filterID), nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// getQueryJQL returns JQL of issues matching saved filter and query in
+// the project, at least one of them should be specified.
+func getQueryJQL(filterID int, query string, project string) (string, error) {
+	if filterID == 0 && query == "" {
+		return "", errors.New("query (-q) or filter (-f) should be specified")
+	}
+
+	chunks := []string{}
+
+	if filterID != 0 {
+		filter, err := getFilter(filterID)
+		if err != nil {
+			return "", karma.Format(
+				err,
+				"unable to get filter: %d", filterID,
+			)
+		}
+
+		conditions, _ := splitOrderBy(filter.JQL)
+		if conditions != "" {
+			chunks = append(chunks, "("+conditions+")")
+		}
+	}
+
+	if query != "" {
+		chunks = append(chunks, "("+query+")")
+	}
+
+	if project != "" {
+		chunks = append(chunks, "project = "+project)
+	}
+
+	return strings.Join(chunks, " AND "), nil
+}
+
+var reOrderBy = regexp.MustCompile(`(?i)\s*\border\s+by\s+`)
+
+// splitOrderBy splits given JQL into conditions and ORDER BY clause
+// contents.
+func splitOrderBy(jql string) (string, string) {
+	matches := reOrderBy.FindAllStringIndex(jql, -1)
+	if len(matches) == 0 {
+		return strings.TrimSpace(jql), ""
+	}
+
+	last := matches[len(matches)-1]
+
+	return strings.TrimSpace(jql[:last[0]]), strings.TrimSpace(jql[last[1]:])
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// handleSetMode sets field of specified issues to the value. Values of array
+// fields can be added or removed by prefixing them with + or -, like
+// +urgent,-stale.
+func handleSetMode(fieldName string, value string, keys []string) error {
+	failed := 0
+	for _, key := range keys {
+		err := setIssueField(key, fieldName, value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", key, err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("%s: %s updated\n", key, fieldName)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d issues are not updated", failed, len(keys))
+	}
+
+	return nil
+}
+
+func setIssueField(key string, fieldName string, value string) error {
+	metaFields, err := getEditMetaFields(key)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get editable fields",
+		)
+	}
+
+	id, field, ok := findMetaField(fieldName, metaFields)
+	if !ok {
+		return fmt.Errorf("field %s can't be edited", fieldName)
+	}
+
+	payload, err := getFieldUpdate(id, field, value)
+	if err != nil {
+		return err
+	}
+
+	return requestAPI("PUT", "/issue/"+key, payload, nil)
+}
+
+// getFieldUpdate returns payload of issue update request which sets the
+// field value or adds and removes values of array field.
+func getFieldUpdate(
+	id string,
+	field metaField,
+	value string,
+) (map[string]interface{}, error) {
+	value = strings.TrimSpace(value)
+
+	if field.Schema.Type == "array" {
+		operations, err := getArrayOperations(field, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if operations != nil {
+			return map[string]interface{}{
+				"update": map[string]interface{}{id: operations},
+			}, nil
+		}
+
+		items := []string{}
+		for _, item := range splitList(value) {
+			item, err = getAllowedValue(field, item)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		value = strings.Join(items, ",")
+	} else if value != "" {
+		var err error
+		value, err = getAllowedValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	coerced, err := coerceFieldValue(field.Schema, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of field %s: %s", field.Name, err)
+	}
+
+	return map[string]interface{}{
+		"fields": map[string]interface{}{id: coerced},
+	}, nil
+}
+
+// getArrayOperations returns add and remove operations for value like
+// +urgent,-stale or nil if value has no such prefixes.
+func getArrayOperations(field metaField, value string) ([]interface{}, error) {
+	items := splitList(value)
+
+	prefixed := 0
+	for _, item := range items {
+		if strings.HasPrefix(item, "+") || strings.HasPrefix(item, "-") {
+			prefixed++
+		}
+	}
+
+	if prefixed == 0 {
+		return nil, nil
+	}
+
+	if prefixed != len(items) {
+		return nil, fmt.Errorf(
+			"all values should be prefixed with + or - to add or remove " +
+				"them or none of them to replace field value",
+		)
+	}
+
+	operations := []interface{}{}
+	for _, item := range items {
+		operation := "add"
+		if item[0] == '-' {
+			operation = "remove"
+		}
+
+		item, err := getAllowedValue(field, strings.TrimSpace(item[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		coerced, err := coerceFieldValue(fieldSchema{Type: field.Schema.Items}, item)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid value of field %s: %s", field.Name, err,
+			)
+		}
+
+		operations = append(
+			operations,
+			map[string]interface{}{operation: coerced},
+		)
+	}
+
+	return operations, nil
+}
+
+// getAllowedValue returns allowed value of the field which matches
+// specified one case-insensitively.
+func getAllowedValue(field metaField, value string) (string, error) {
+	if len(field.AllowedValues) == 0 {
+		return value, nil
+	}
+
+	names := []string{}
+	for _, allowed := range field.AllowedValues {
+		name := allowed.Name
+		if name == "" {
+			name = allowed.Value
+		}
+
+		if strings.EqualFold(name, value) {
+			return name, nil
+		}
+
+		names = append(names, name)
+	}
+
+	return "", fmt.Errorf(
+		"%q is not allowed value of field %s, expected one of: %s",
+		value, field.Name, strings.Join(names, ", "),
+	)
+}
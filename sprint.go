@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/reconquest/karma-go"
+)
+
+func handleSprintListMode(boardID int, states string) error {
+	sprints, err := getSprints(boardID, states)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get sprints of board: %d", boardID,
+		)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	for _, sprint := range sprints {
+		fmt.Fprintf(
+			writer, "%d\t%s\t%s\t%s\t%s\n",
+			sprint.ID,
+			sprint.State,
+			sprint.Name,
+			formatSprintDates(sprint),
+			sprint.Goal,
+		)
+	}
+
+	return writer.Flush()
+}
+
+// handleSprintMoveMode moves issues to specified sprint or to the backlog if
+// sprint name is empty.
+func handleSprintMoveMode(boardID int, sprintName string, keys []string) error {
+	var sprint *agileSprint
+	if sprintName != "" {
+		var err error
+		sprint, err = findSprint(boardID, sprintName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := moveIssuesToSprint(sprint, keys)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to move issues: %s", strings.Join(keys, ", "),
+		)
+	}
+
+	target := "backlog"
+	if sprint != nil {
+		target = "sprint " + sprint.Name
+	}
+
+	fmt.Printf("Issues %s moved to %s\n", strings.Join(keys, ", "), target)
+
+	return nil
+}
+
+// handleSprintSummaryMode shows amount of issues and story points which are
+// currently in the sprint and how many of them are completed, subtasks are
+// not counted. Issues are considered completed if they are in the last
+// column of the board like Jira does.
+func handleSprintSummaryMode(boardID int, sprintName string) error {
+	var sprint *agileSprint
+	if sprintName != "" {
+		var err error
+		sprint, err = findSprint(boardID, sprintName)
+		if err != nil {
+			return err
+		}
+	} else {
+		sprints, err := getActiveSprints(boardID)
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to get active sprints of board: %d", boardID,
+			)
+		}
+
+		if len(sprints) == 0 {
+			return fmt.Errorf("board %d has no active sprint", boardID)
+		}
+
+		sprint = &sprints[0]
+	}
+
+	configuration, err := getAgileBoardConfiguration(boardID)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get configuration of board: %d", boardID,
+		)
+	}
+
+	stages, err := getAgileColumnStages(configuration)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get columns of board: %d", boardID,
+		)
+	}
+
+	if len(stages) == 0 {
+		return fmt.Errorf("board %d has no columns", boardID)
+	}
+
+	doneStage := stages[len(stages)-1]
+
+	estimationField := configuration.Estimation.Field.FieldID
+
+	fields := append([]string{}, searchFields...)
+	if estimationField != "" {
+		fields = append(fields, estimationField)
+	}
+
+	issues, err := getAllSprintIssues(
+		boardID, sprint.ID,
+		"?fields="+strings.Join(fields, ",")+
+			"&maxResults="+strconv.Itoa(searchPageSize),
+	)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get issues of sprint: %s", sprint.Name,
+		)
+	}
+
+	var (
+		total           int
+		completed       int
+		points          float64
+		completedPoints float64
+	)
+
+	for _, issue := range issues {
+		if issue.Fields.IssueType.Subtask {
+			continue
+		}
+
+		total++
+
+		var estimate float64
+		if estimationField != "" {
+			_, err := issue.GetField(estimationField, &estimate)
+			if err != nil {
+				return karma.Format(
+					err,
+					"unable to get estimate of issue: %s", issue.Key,
+				)
+			}
+		}
+
+		points += estimate
+
+		if doneStage.HasStatus(issue.Fields.Status.Name) {
+			completed++
+			completedPoints += estimate
+		}
+	}
+
+	fmt.Printf(
+		"%s (%s) %s\n",
+		sprint.Name, sprint.State, formatSprintDates(*sprint),
+	)
+	if sprint.Goal != "" {
+		fmt.Printf("Goal:       %s\n", sprint.Goal)
+	}
+
+	fmt.Printf("Issues:     %d completed of %d in sprint\n", completed, total)
+
+	if estimationField != "" {
+		fmt.Printf(
+			"Estimates:  %g completed of %g in sprint (%s)\n",
+			completedPoints, points,
+			configuration.Estimation.Field.DisplayName,
+		)
+	}
+
+	return nil
+}
+
+func formatSprintDates(sprint agileSprint) string {
+	if sprint.StartDate == "" {
+		return ""
+	}
+
+	return formatTime(sprint.StartDate) + " - " + formatTime(sprint.EndDate)
+}
@@ -3,16 +3,14 @@ package main
 import (
 	"fmt"
 	"sort"
-
-	"github.com/tears-of-noobs/gojira"
 )
 
-func getWorkflowStageStatusOrder(issue gojira.Issue, workflowStages []Stage) int {
+func getWorkflowStageStatusOrder(issue Issue, workflowStages []Stage) int {
 	if len(workflowStages) == 0 {
 		return 1
 	}
 	for _, stage := range workflowStages {
-		if stage.Name == issue.Fields.Status.Name {
+		if stage.HasStatus(issue.Fields.Status.Name) {
 			return stage.Order
 		}
 	}
@@ -22,7 +20,7 @@ func getWorkflowStageStatusOrder(issue gojira.Issue, workflowStages []Stage) int
 }
 
 type StatusSortableIssues struct {
-	Issues []gojira.Issue
+	Issues []Issue
 	Stages []Stage
 }
 
@@ -40,7 +38,7 @@ func (sortable StatusSortableIssues) Less(i, j int) bool {
 		getWorkflowStageStatusOrder(sortable.Issues[j], sortable.Stages)
 }
 
-func sortIssuesByStatus(issues []gojira.Issue, stages []Stage) []gojira.Issue {
+func sortIssuesByStatus(issues []Issue, stages []Stage) []Issue {
 	sortable := StatusSortableIssues{
 		Issues: issues,
 		Stages: stages,
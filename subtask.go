@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// getSubtasks returns subtasks of specified issue with all fields which are
+// used in issue list.
+func getSubtasks(issue *Issue) ([]Issue, error) {
+	if len(issue.Extra.Subtasks) == 0 {
+		return []Issue{}, nil
+	}
+
+	search, err := getIssues(
+		"parent = "+issue.Key+" ORDER BY created ASC",
+		len(issue.Extra.Subtasks),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return search.Issues, nil
+}
+
+func handleSubtaskCreateMode(
+	parent *Issue,
+	issueType string,
+	config *Configuration,
+	templateName string,
+	options createOptions,
+) error {
+	if issueType == "" {
+		var err error
+		issueType, err = getSubtaskType()
+		if err != nil {
+			return karma.Format(
+				err,
+				"unable to get subtask issue type",
+			)
+		}
+	}
+
+	project := strings.SplitN(parent.Key, "-", 2)[0]
+
+	template, ok := config.GetCreateTemplate(templateName, project, issueType)
+	if !ok && templateName != "" {
+		return fmt.Errorf("create template not found: %s", templateName)
+	}
+
+	return handleCreateMode(
+		project, issueType, parent.Key, template.Template, options,
+	)
+}
+
+func handleSubtaskListMode(
+	parent *Issue,
+	config *Configuration,
+	showName bool,
+	onlySummary bool,
+) error {
+	subtasks, err := getSubtasks(parent)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to get subtasks of issue: %s", parent.Key,
+		)
+	}
+
+	activeIssueKey, err := getActiveIssueKey()
+	if err != nil {
+		return err
+	}
+
+	return displayIssues(
+		subtasks,
+		activeIssueKey, showName, onlySummary,
+		config.Workflow,
+		nil,
+		nil,
+	)
+}
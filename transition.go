@@ -0,0 +1,310 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// transition is an issue transition with fields of its screen.
+type transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"to"`
+	Fields map[string]metaField `json:"fields"`
+}
+
+// transitionOptions are values of transition screen fields and comment
+// which are set during transition.
+type transitionOptions struct {
+	fields  map[string]string
+	comment string
+
+	// prompt enables asking user for required fields which are not
+	// specified.
+	prompt bool
+}
+
+// getRequiredFields returns identifiers of fields which should be specified
+// for transition, sorted by field names.
+func (transition transition) getRequiredFields() []string {
+	ids := []string{}
+	for id, field := range transition.Fields {
+		if field.Required && !field.HasDefaultValue {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return transition.Fields[ids[i]].Name < transition.Fields[ids[j]].Name
+	})
+
+	return ids
+}
+
+func getTransitions(issueKey string) ([]transition, error) {
+	var result struct {
+		Transitions []transition `json:"transitions"`
+	}
+
+	err := requestAPI(
+		"GET",
+		"/issue/"+issueKey+"/transitions?expand=transitions.fields",
+		nil, &result,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Transitions, nil
+}
+
+// transitionIssue moves issue using transition specified by identifier,
+// transition name, target status name or name of workflow stage. It returns
+// transition which is used.
+func transitionIssue(
+	issue *Issue,
+	query string,
+	workflow Workflow,
+	options transitionOptions,
+) (*transition, error) {
+	transitions, err := getTransitions(issue.Key)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to get transitions of issue: %s", issue.Key,
+		)
+	}
+
+	found, err := findTransition(transitions, query, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := getTransitionPayload(found, options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = requestAPI("POST", "/issue/"+issue.Key+"/transitions", payload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// getTransitionPayload returns transition request with values of screen
+// fields and comment, required fields which are not specified are asked
+// if prompt is enabled.
+func getTransitionPayload(
+	transition *transition,
+	options transitionOptions,
+) (map[string]interface{}, error) {
+	values := map[string]string{}
+	for key, value := range options.fields {
+		id, _, ok := findMetaField(key, transition.Fields)
+		if !ok {
+			return nil, fmt.Errorf(
+				"field %s is not on screen of transition %s",
+				key, transition.Name,
+			)
+		}
+
+		values[id] = value
+	}
+
+	for _, id := range transition.getRequiredFields() {
+		if _, ok := values[id]; ok {
+			continue
+		}
+
+		field := transition.Fields[id]
+
+		if !options.prompt {
+			return nil, fmt.Errorf(
+				"field %s is required by transition %s",
+				field.Name, transition.Name,
+			)
+		}
+
+		value, err := askField(field)
+		if err != nil {
+			return nil, err
+		}
+
+		values[id] = value
+	}
+
+	fields := map[string]interface{}{}
+	update := map[string]interface{}{}
+	for id, value := range values {
+		fieldUpdate, err := getFieldUpdate(id, transition.Fields[id], value)
+		if err != nil {
+			return nil, err
+		}
+
+		if changes, ok := fieldUpdate["fields"]; ok {
+			for key, change := range changes.(map[string]interface{}) {
+				fields[key] = change
+			}
+		}
+
+		if operations, ok := fieldUpdate["update"]; ok {
+			for key, operation := range operations.(map[string]interface{}) {
+				update[key] = operation
+			}
+		}
+	}
+
+	if options.comment != "" {
+		update["comment"] = []interface{}{
+			map[string]interface{}{
+				"add": map[string]string{"body": options.comment},
+			},
+		}
+	}
+
+	payload := map[string]interface{}{
+		"transition": map[string]string{"id": transition.ID},
+	}
+
+	if len(fields) > 0 {
+		payload["fields"] = fields
+	}
+
+	if len(update) > 0 {
+		payload["update"] = update
+	}
+
+	return payload, nil
+}
+
+// askField asks user for value of required field.
+func askField(field metaField) (string, error) {
+	prompt := field.Name
+	if len(field.AllowedValues) > 0 {
+		names := []string{}
+		for _, allowed := range field.AllowedValues {
+			name := allowed.Name
+			if name == "" {
+				name = allowed.Value
+			}
+
+			names = append(names, name)
+		}
+
+		prompt += " (" + strings.Join(names, ", ") + ")"
+	}
+
+	value, err := ask(prompt + ": ")
+	if err != nil {
+		return "", err
+	}
+
+	if value == "" {
+		return "", fmt.Errorf("field %s is required", field.Name)
+	}
+
+	return value, nil
+}
+
+// findTransition returns transition matching specified query. Query is
+// matched case-insensitively with transition identifier, name and target
+// status name, then with name of workflow stage which contains target status
+// and finally with beginning of transition or target status name. It
+// returns error with matching transitions if query is ambiguous.
+func findTransition(
+	transitions []transition,
+	query string,
+	workflow Workflow,
+) (*transition, error) {
+	query = strings.TrimSpace(query)
+
+	matchers := []func(transition transition) bool{
+		func(transition transition) bool {
+			return transition.ID == query
+		},
+		func(transition transition) bool {
+			return strings.EqualFold(transition.Name, query) ||
+				strings.EqualFold(transition.To.Name, query)
+		},
+		func(transition transition) bool {
+			for _, stage := range workflow.Stages {
+				if strings.EqualFold(stage.Name, query) &&
+					stage.HasStatus(transition.To.Name) {
+					return true
+				}
+			}
+
+			return false
+		},
+		func(transition transition) bool {
+			prefix := strings.ToLower(query)
+
+			return strings.HasPrefix(strings.ToLower(transition.Name), prefix) ||
+				strings.HasPrefix(strings.ToLower(transition.To.Name), prefix)
+		},
+	}
+
+	for _, match := range matchers {
+		candidates := []transition{}
+		for _, transition := range transitions {
+			if match(transition) {
+				candidates = append(candidates, transition)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return &candidates[0], nil
+		default:
+			return nil, fmt.Errorf(
+				"transition %q is ambiguous, candidates:\n%s",
+				query, formatTransitions(candidates),
+			)
+		}
+	}
+
+	if len(transitions) == 0 {
+		return nil, errors.New("no transitions are available")
+	}
+
+	return nil, fmt.Errorf(
+		"transition %q is not found, available transitions:\n%s",
+		query, formatTransitions(transitions),
+	)
+}
+
+// formatTransitions returns transitions one per line with their target
+// statuses and required fields.
+func formatTransitions(transitions []transition) string {
+	lines := []string{}
+	for _, transition := range transitions {
+		line := fmt.Sprintf("%3s %s", transition.ID, transition.To.Name)
+		if transition.Name != transition.To.Name {
+			line += " (" + transition.Name + ")"
+		}
+
+		required := []string{}
+		for _, id := range transition.getRequiredFields() {
+			required = append(required, transition.Fields[id].Name)
+		}
+
+		if len(required) > 0 {
+			line += ", requires: " + strings.Join(required, ", ")
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}